@@ -13,7 +13,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/batx-dev/virtual-kubelet-apptainer/internal/podresources"
 	apptainerprovider "github.com/batx-dev/virtual-kubelet-apptainer/internal/provider"
+	"github.com/batx-dev/virtual-kubelet-apptainer/internal/readiness"
 	"github.com/mitchellh/go-homedir"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -44,6 +46,22 @@ var (
 	logLevel        = "info"
 	numberOfWorkers = 50
 	resync          time.Duration
+
+	runtime        = apptainerprovider.RuntimeLocal
+	slurmPartition string
+	slurmQOS       string
+	slurmAccount   string
+	slurmTimeLimit = "01:00:00"
+	slurmNodes     = "1"
+	slurmWorkDir   string
+
+	// podResourcesSocket is off by default; set it to e.g.
+	// /var/lib/kubelet/pod-resources/vk-apptainer.sock to advertise device
+	// assignments to device-plugin-aware workloads.
+	podResourcesSocket string
+
+	readinessWorkers      = 4
+	readinessProbeTimeout = 5 * time.Second
 )
 
 func main() {
@@ -98,6 +116,16 @@ func main() {
 	flags.StringVar(&logLevel, "log-level", logLevel, "log level.")
 	flags.IntVar(&numberOfWorkers, "pod-sync-workers", numberOfWorkers, `set the number of pod synchronization workers`)
 	flags.DurationVar(&resync, "full-resync-period", resync, "how often to perform a full resync of pods between kubernetes and the provider")
+	flags.StringVar(&runtime, "runtime", runtime, `pod execution backend: "local" (apptainer instance on this host) or "slurm" (sbatch job wrapping apptainer)`)
+	flags.StringVar(&slurmPartition, "slurm-partition", slurmPartition, "default SLURM partition for the slurm runtime")
+	flags.StringVar(&slurmQOS, "slurm-qos", slurmQOS, "default SLURM QOS for the slurm runtime")
+	flags.StringVar(&slurmAccount, "slurm-account", slurmAccount, "default SLURM account for the slurm runtime")
+	flags.StringVar(&slurmTimeLimit, "slurm-time-limit", slurmTimeLimit, "default SLURM time limit for the slurm runtime")
+	flags.StringVar(&slurmNodes, "slurm-nodes", slurmNodes, "default SLURM node count for the slurm runtime")
+	flags.StringVar(&slurmWorkDir, "slurm-work-dir", slurmWorkDir, "directory for generated sbatch scripts and job output")
+	flags.StringVar(&podResourcesSocket, "pod-resources-socket", podResourcesSocket, "unix socket path to serve the PodResourcesLister gRPC API on; disabled when empty")
+	flags.IntVar(&readinessWorkers, "readiness-workers", readinessWorkers, "number of readiness/liveness probes that may run concurrently across all pods")
+	flags.DurationVar(&readinessProbeTimeout, "readiness-probe-timeout", readinessProbeTimeout, "timeout for a single readiness/liveness probe invocation")
 
 	if err := cmd.ExecuteContext(ctx); err != nil {
 		if !errors.Is(err, context.Canceled) {
@@ -107,6 +135,8 @@ func main() {
 }
 
 func run(ctx context.Context) error {
+	var provider *apptainerprovider.ApptainerProvider
+
 	node, err := nodeutil.NewNode(nodeName,
 		// with provider
 		func(cfg nodeutil.ProviderConfig) (nodeutil.Provider, node.NodeProvider, error) {
@@ -118,9 +148,37 @@ func run(ctx context.Context) error {
 				}
 			}
 
-			p, err := apptainerprovider.NewApptainerProvider(ctx, nodeName, operatingSystem, os.Getenv("VKUBELET_POD_IP"), int32(listenPort))
+			if slurmWorkDir == "" {
+				stateHome := os.Getenv("XDG_STATE_HOME")
+				if stateHome == "" {
+					home, _ := homedir.Dir()
+					stateHome = filepath.Join(home, ".local", "state")
+				}
+				slurmWorkDir = filepath.Join(stateHome, "vk-apptainer", "slurm")
+			}
+
+			runtimeCfg := apptainerprovider.RuntimeConfig{
+				Default:        runtime,
+				SlurmPartition: slurmPartition,
+				SlurmQOS:       slurmQOS,
+				SlurmAccount:   slurmAccount,
+				SlurmTimeLimit: slurmTimeLimit,
+				SlurmNodes:     slurmNodes,
+				SlurmWorkDir:   slurmWorkDir,
+			}
+
+			readinessCfg := readiness.Config{
+				Workers: readinessWorkers,
+				Timeout: readinessProbeTimeout,
+			}
+
+			p, err := apptainerprovider.NewApptainerProvider(ctx, nodeName, operatingSystem, os.Getenv("VKUBELET_POD_IP"), int32(listenPort), runtimeCfg, readinessCfg)
+			if err != nil {
+				return nil, nil, err
+			}
 			p.ConfigureNode(ctx, cfg.Node)
-			return p, nil, err
+			provider = p
+			return p, nil, nil
 		},
 		// with client
 		func(cfg *nodeutil.NodeConfig) error {
@@ -183,6 +241,15 @@ func run(ctx context.Context) error {
 		return nil
 	}()
 
+	if podResourcesSocket != "" {
+		server := podresources.NewServer(provider)
+		go func() {
+			if err := podresources.Serve(ctx, podResourcesSocket, server); err != nil {
+				log.G(ctx).WithError(err).Error("pod resources server exited")
+			}
+		}()
+	}
+
 	if err := node.WaitReady(ctx, startupTimeout); err != nil {
 		return fmt.Errorf("error waiting for node to be ready: %w", err)
 	}
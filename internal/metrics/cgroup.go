@@ -0,0 +1,156 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	stats "github.com/virtual-kubelet/virtual-kubelet/node/api/statsv1alpha1"
+)
+
+const cgroupRoot = "/sys/fs/cgroup"
+
+// cgroupPaths resolves the cgroup v1 controller directories (keyed by
+// single controller name, e.g. "cpuacct", "memory"; a co-mounted
+// "cpu,cpuacct" hierarchy yields one entry per controller) or, on a cgroup
+// v2 host, the single unified path, for pid.
+func cgroupPaths(pid int) (unified string, v1 map[string]string, err error) {
+	f, err := os.Open(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+
+	v1 = make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// Format: hierarchy-ID:controller-list:path
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+
+		if parts[0] == "0" && parts[1] == "" {
+			unified = filepath.Join(cgroupRoot, parts[2])
+			continue
+		}
+		for _, controller := range strings.Split(parts[1], ",") {
+			v1[controller] = filepath.Join(cgroupRoot, controller, parts[2])
+		}
+	}
+	return unified, v1, scanner.Err()
+}
+
+// readCgroupCPU returns CPU usage stats for pid, along with the raw
+// cumulative nanosecond counter used to derive a rate across scrapes.
+func readCgroupCPU(pid int) (stats.CPUStats, uint64, error) {
+	unified, v1, err := cgroupPaths(pid)
+	if err != nil {
+		return stats.CPUStats{}, 0, err
+	}
+
+	var usageNanos uint64
+	switch {
+	case unified != "":
+		fields, err := readKeyedFile(filepath.Join(unified, "cpu.stat"))
+		if err != nil {
+			return stats.CPUStats{}, 0, err
+		}
+		usageNanos = fields["usage_usec"] * 1000
+	case v1["cpu,cpuacct"] != "" || v1["cpuacct"] != "":
+		dir := v1["cpu,cpuacct"]
+		if dir == "" {
+			dir = v1["cpuacct"]
+		}
+		usageNanos, err = readUintFile(filepath.Join(dir, "cpuacct.usage"))
+		if err != nil {
+			return stats.CPUStats{}, 0, err
+		}
+	default:
+		return stats.CPUStats{}, 0, fmt.Errorf("no cpu cgroup found for pid %d", pid)
+	}
+
+	return stats.CPUStats{UsageCoreNanoSeconds: &usageNanos}, usageNanos, nil
+}
+
+// readCgroupMemory returns memory usage stats for pid. WorkingSetBytes
+// approximates the kubelet's definition as usage minus reclaimable file
+// cache.
+func readCgroupMemory(pid int) (stats.MemoryStats, error) {
+	unified, v1, err := cgroupPaths(pid)
+	if err != nil {
+		return stats.MemoryStats{}, err
+	}
+
+	var usage, cache uint64
+	switch {
+	case unified != "":
+		usage, err = readUintFile(filepath.Join(unified, "memory.current"))
+		if err != nil {
+			return stats.MemoryStats{}, err
+		}
+		fields, err := readKeyedFile(filepath.Join(unified, "memory.stat"))
+		if err == nil {
+			cache = fields["inactive_file"] + fields["active_file"]
+		}
+	case v1["memory"] != "":
+		dir := v1["memory"]
+		usage, err = readUintFile(filepath.Join(dir, "memory.usage_in_bytes"))
+		if err != nil {
+			return stats.MemoryStats{}, err
+		}
+		fields, err := readKeyedFile(filepath.Join(dir, "memory.stat"))
+		if err == nil {
+			cache = fields["cache"]
+		}
+	default:
+		return stats.MemoryStats{}, fmt.Errorf("no memory cgroup found for pid %d", pid)
+	}
+
+	workingSet := usage
+	if cache < usage {
+		workingSet = usage - cache
+	}
+
+	return stats.MemoryStats{
+		UsageBytes:      &usage,
+		WorkingSetBytes: &workingSet,
+	}, nil
+}
+
+// readKeyedFile parses the "key value" per-line format used by cgroup stat
+// files such as cpu.stat and memory.stat. Lines whose last field is not a
+// bare integer (e.g. io.stat's key=value fields) are skipped.
+func readKeyedFile(path string) (map[string]uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	out := make(map[string]uint64)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		v, err := strconv.ParseUint(fields[len(fields)-1], 10, 64)
+		if err != nil {
+			continue
+		}
+		out[fields[0]] = v
+	}
+	return out, scanner.Err()
+}
+
+func readUintFile(path string) (uint64, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(b)), 10, 64)
+}
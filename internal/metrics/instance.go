@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+)
+
+type apptainerInstance struct {
+	Instance string `json:"instance"`
+	Pid      int    `json:"pid"`
+}
+
+type apptainerInstanceList struct {
+	Instances []apptainerInstance `json:"instances"`
+}
+
+// instancePID resolves a pod/container to the PID of the Apptainer instance
+// backing it, by matching the naming convention the provider package uses
+// ("<namespace>_<pod>_<container>") against `apptainer instance list --json`.
+func instancePID(namespace, podName, containerName string) (int, error) {
+	out, err := exec.Command("apptainer", "instance", "list", "--json").Output()
+	if err != nil {
+		return 0, fmt.Errorf("listing apptainer instances: %w", err)
+	}
+
+	var list apptainerInstanceList
+	if err := json.Unmarshal(out, &list); err != nil {
+		return 0, fmt.Errorf("decoding apptainer instance list: %w", err)
+	}
+
+	want := fmt.Sprintf("%s_%s_%s", namespace, podName, containerName)
+	for _, inst := range list.Instances {
+		if inst.Instance == want {
+			return inst.Pid, nil
+		}
+	}
+	return 0, fmt.Errorf("no apptainer instance found for %s", want)
+}
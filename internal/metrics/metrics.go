@@ -2,20 +2,191 @@ package metrics
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	stats "github.com/virtual-kubelet/virtual-kubelet/node/api/statsv1alpha1"
 )
 
+// PodSource is how the metrics collector learns which pods/containers it
+// should report on, without needing to import the provider package (which
+// already imports this one).
+type PodSource interface {
+	ListPods() []PodRef
+}
+
+// PodRef is the metrics collector's view of a single running pod.
+type PodRef struct {
+	Namespace  string
+	Name       string
+	UID        string
+	StartTime  time.Time
+	Containers []ContainerRef
+}
+
+// ContainerRef is the metrics collector's view of a single container. GPU
+// is set when the container requested nvidia.com/gpu, so GetStatsSummary
+// knows to shell out to nvidia-smi for it.
+type ContainerRef struct {
+	Name      string
+	StartTime time.Time
+	GPU       bool
+}
+
+// cpuSample is the last cgroup CPU usage we observed for a container, kept
+// around so back-to-back scrapes can report a rate (UsageNanoCores) instead
+// of just the monotonically increasing counter cgroups expose.
+type cpuSample struct {
+	at         time.Time
+	usageNanos uint64
+}
+
 type ApptaienrMetricsProvider struct {
+	nodeName  string
+	podSource PodSource
+
+	mu      sync.Mutex
+	samples map[string]cpuSample // keyed by namespace/pod/container
 }
 
-func NewApptaienrMetricsProver() *ApptaienrMetricsProvider {
-	p := &ApptaienrMetricsProvider{}
+func NewApptaienrMetricsProver(nodeName string, podSource PodSource) *ApptaienrMetricsProvider {
+	p := &ApptaienrMetricsProvider{
+		nodeName:  nodeName,
+		podSource: podSource,
+		samples:   make(map[string]cpuSample),
+	}
 	return p
 }
 
-// GetStatsSummary returns the stats summary for pods running on ACI
+// GetStatsSummary returns the stats summary for pods running under this
+// provider, collected from each container's Apptainer instance cgroup and
+// /proc.
 func (p *ApptaienrMetricsProvider) GetStatsSummary(ctx context.Context) (summary *stats.Summary, err error) {
-	s := &stats.Summary{}
+	now := time.Now()
+	s := &stats.Summary{
+		Node: stats.NodeStats{
+			NodeName:  p.nodeName,
+			StartTime: v1Time(now),
+		},
+	}
+
+	for _, pod := range p.podSource.ListPods() {
+		podStats := stats.PodStats{
+			PodRef: stats.PodReference{
+				Name:      pod.Name,
+				Namespace: pod.Namespace,
+				UID:       pod.UID,
+			},
+			StartTime: v1Time(pod.StartTime),
+		}
+
+		for _, c := range pod.Containers {
+			cs, netStat, err := p.containerStats(pod, c, now)
+			if err != nil {
+				// A container we can't currently find a PID for (not yet
+				// started, or just exited) simply contributes no stats
+				// rather than failing the whole scrape.
+				continue
+			}
+			podStats.Containers = append(podStats.Containers, cs)
+			if podStats.Network == nil && netStat != nil {
+				podStats.Network = netStat
+			}
+		}
+
+		podStats.CPU, podStats.Memory = sumContainerStats(podStats.Containers, now)
+		s.Pods = append(s.Pods, podStats)
+	}
+
 	return s, nil
 }
+
+// containerStats resolves pod/container to its Apptainer instance PID and
+// builds a ContainerStats from that PID's cgroup and /proc state, plus a
+// NetworkStats if /proc/<pid>/net/dev was readable.
+func (p *ApptaienrMetricsProvider) containerStats(pod PodRef, c ContainerRef, now time.Time) (stats.ContainerStats, *stats.NetworkStats, error) {
+	pid, err := instancePID(pod.Namespace, pod.Name, c.Name)
+	if err != nil {
+		return stats.ContainerStats{}, nil, err
+	}
+
+	cpuStat, usageNanos, err := readCgroupCPU(pid)
+	if err != nil {
+		return stats.ContainerStats{}, nil, err
+	}
+
+	key := pod.Namespace + "/" + pod.Name + "/" + c.Name
+	rate := p.cpuRate(key, usageNanos, now)
+	cpuStat.Time = v1Time(now)
+	cpuStat.UsageNanoCores = &rate
+
+	memStat, err := readCgroupMemory(pid)
+	if err != nil {
+		return stats.ContainerStats{}, nil, err
+	}
+	memStat.Time = v1Time(now)
+
+	cs := stats.ContainerStats{
+		Name:      c.Name,
+		StartTime: v1Time(c.StartTime),
+		CPU:       &cpuStat,
+		Memory:    &memStat,
+	}
+
+	if c.GPU {
+		if metrics, err := nvidiaSMIMetrics(); err == nil {
+			cs.UserDefinedMetrics = metrics
+		}
+	}
+
+	var netStat *stats.NetworkStats
+	if ns, err := readProcNetDev(pid); err == nil {
+		ns.Time = v1Time(now)
+		netStat = &ns
+	}
+
+	return cs, netStat, nil
+}
+
+// cpuRate turns a monotonically increasing cgroup CPU usage counter into a
+// nanocores rate (CPU nanoseconds used per wall-clock second) by diffing
+// against the last sample taken for this container.
+func (p *ApptaienrMetricsProvider) cpuRate(key string, usageNanos uint64, now time.Time) uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	prev, ok := p.samples[key]
+	p.samples[key] = cpuSample{at: now, usageNanos: usageNanos}
+	if !ok || usageNanos < prev.usageNanos {
+		return 0
+	}
+
+	elapsed := now.Sub(prev.at).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return uint64(float64(usageNanos-prev.usageNanos) / elapsed)
+}
+
+// sumContainerStats aggregates per-container CPU/Memory stats up to the pod
+// level, the way cadvisor-backed kubelets do.
+func sumContainerStats(containers []stats.ContainerStats, now time.Time) (*stats.CPUStats, *stats.MemoryStats) {
+	var totalNanoCores, totalUsageBytes, totalWorkingSet uint64
+	for _, c := range containers {
+		if c.CPU != nil && c.CPU.UsageNanoCores != nil {
+			totalNanoCores += *c.CPU.UsageNanoCores
+		}
+		if c.Memory != nil {
+			if c.Memory.UsageBytes != nil {
+				totalUsageBytes += *c.Memory.UsageBytes
+			}
+			if c.Memory.WorkingSetBytes != nil {
+				totalWorkingSet += *c.Memory.WorkingSetBytes
+			}
+		}
+	}
+
+	t := v1Time(now)
+	return &stats.CPUStats{Time: t, UsageNanoCores: &totalNanoCores},
+		&stats.MemoryStats{Time: t, UsageBytes: &totalUsageBytes, WorkingSetBytes: &totalWorkingSet}
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	stats "github.com/virtual-kubelet/virtual-kubelet/node/api/statsv1alpha1"
+)
+
+// readProcNetDev parses /proc/<pid>/net/dev into per-interface counters.
+// The first interface found (after loopback) is reported as the summary
+// InterfaceStats, matching how the kubelet picks a "primary" interface.
+func readProcNetDev(pid int) (stats.NetworkStats, error) {
+	f, err := os.Open(fmt.Sprintf("/proc/%d/net/dev", pid))
+	if err != nil {
+		return stats.NetworkStats{}, err
+	}
+	defer f.Close()
+
+	var ns stats.NetworkStats
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.Contains(line, ":") {
+			continue // header lines
+		}
+
+		parts := strings.SplitN(line, ":", 2)
+		name := strings.TrimSpace(parts[0])
+		fields := strings.Fields(parts[1])
+		if name == "lo" || len(fields) < 16 {
+			continue
+		}
+
+		rxBytes, _ := strconv.ParseUint(fields[0], 10, 64)
+		rxErrors, _ := strconv.ParseUint(fields[2], 10, 64)
+		txBytes, _ := strconv.ParseUint(fields[8], 10, 64)
+		txErrors, _ := strconv.ParseUint(fields[10], 10, 64)
+
+		iface := stats.InterfaceStats{
+			Name:     name,
+			RxBytes:  &rxBytes,
+			RxErrors: &rxErrors,
+			TxBytes:  &txBytes,
+			TxErrors: &txErrors,
+		}
+		ns.Interfaces = append(ns.Interfaces, iface)
+		if ns.InterfaceStats.Name == "" {
+			ns.InterfaceStats = iface
+		}
+	}
+	return ns, scanner.Err()
+}
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	stats "github.com/virtual-kubelet/virtual-kubelet/node/api/statsv1alpha1"
+)
+
+// nvidiaQueryFields are the nvidia-smi --query-gpu columns we surface as
+// UserDefinedMetrics.
+var nvidiaQueryFields = []string{
+	"utilization.gpu",
+	"utilization.memory",
+	"memory.used",
+	"temperature.gpu",
+}
+
+// nvidiaSMIMetrics shells out to nvidia-smi for the host's GPU utilization
+// and memory counters. nvidia-smi doesn't expose per-container attribution
+// for --query-gpu, so every GPU-requesting container on the node reports
+// the same node-wide figures, one UserDefinedMetric per GPU and queried
+// field, named "gpu<index>.<field>".
+func nvidiaSMIMetrics() ([]stats.UserDefinedMetric, error) {
+	out, err := exec.Command("nvidia-smi",
+		"--query-gpu="+strings.Join(nvidiaQueryFields, ","),
+		"--format=csv,noheader,nounits").Output()
+	if err != nil {
+		return nil, fmt.Errorf("nvidia-smi: %w", err)
+	}
+
+	now := v1Time(time.Now())
+
+	var metrics []stats.UserDefinedMetric
+	for gpuIndex, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.Split(line, ",")
+		if len(fields) != len(nvidiaQueryFields) {
+			continue
+		}
+
+		for i, name := range nvidiaQueryFields {
+			value, err := strconv.ParseFloat(strings.TrimSpace(fields[i]), 64)
+			if err != nil {
+				continue
+			}
+			metrics = append(metrics, stats.UserDefinedMetric{
+				UserDefinedMetricDescriptor: stats.UserDefinedMetricDescriptor{
+					Name: fmt.Sprintf("gpu%d.%s", gpuIndex, name),
+				},
+				Time:  now,
+				Value: value,
+			})
+		}
+	}
+	return metrics, nil
+}
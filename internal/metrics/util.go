@@ -0,0 +1,11 @@
+package metrics
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func v1Time(t time.Time) metav1.Time {
+	return metav1.NewTime(t)
+}
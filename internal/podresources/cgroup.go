@@ -0,0 +1,125 @@
+package podresources
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const cgroupRoot = "/sys/fs/cgroup"
+
+// cpusetPath resolves pid's cpuset.cpus(.effective) file on either a cgroup
+// v1 or v2 host.
+func cpusetPath(pid int) (string, error) {
+	f, err := os.Open(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var unified, v1 string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// Format: hierarchy-ID:controller-list:path
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if parts[0] == "0" && parts[1] == "" {
+			unified = filepath.Join(cgroupRoot, parts[2])
+			continue
+		}
+		if parts[1] == "cpuset" {
+			v1 = filepath.Join(cgroupRoot, "cpuset", parts[2])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	switch {
+	case unified != "":
+		if path := filepath.Join(unified, "cpuset.cpus.effective"); fileExists(path) {
+			return path, nil
+		}
+		return filepath.Join(unified, "cpuset.cpus"), nil
+	case v1 != "":
+		return filepath.Join(v1, "cpuset.cpus"), nil
+	default:
+		return "", fmt.Errorf("no cpuset cgroup found for pid %d", pid)
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// containerCPUIDs returns the CPU IDs in the cpuset cgroup of the Apptainer
+// instance backing namespace/podName/containerName.
+func containerCPUIDs(namespace, podName, containerName string) ([]int64, error) {
+	pid, err := instancePID(namespace, podName, containerName)
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := cpusetPath(pid)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseCPUList(strings.TrimSpace(string(b)))
+}
+
+// allocatableCPUIDs returns every CPU ID on the node, used to answer
+// GetAllocatableResources regardless of current cpuset assignment.
+func allocatableCPUIDs() ([]int64, error) {
+	ids := make([]int64, runtime.NumCPU())
+	for i := range ids {
+		ids[i] = int64(i)
+	}
+	return ids, nil
+}
+
+// parseCPUList parses the cpuset "N,N-M,..." list format into individual
+// CPU IDs.
+func parseCPUList(s string) ([]int64, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var ids []int64
+	for _, part := range strings.Split(s, ",") {
+		if part == "" {
+			continue
+		}
+		if lo, hi, ok := strings.Cut(part, "-"); ok {
+			loN, err := strconv.ParseInt(lo, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("parsing cpuset range %q: %w", part, err)
+			}
+			hiN, err := strconv.ParseInt(hi, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("parsing cpuset range %q: %w", part, err)
+			}
+			for n := loN; n <= hiN; n++ {
+				ids = append(ids, n)
+			}
+			continue
+		}
+		n, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing cpuset entry %q: %w", part, err)
+		}
+		ids = append(ids, n)
+	}
+	return ids, nil
+}
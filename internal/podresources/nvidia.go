@@ -0,0 +1,66 @@
+package podresources
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// nvidiaSMIListPattern matches a line of `nvidia-smi -L` output, e.g.
+// "GPU 0: Tesla T4 (UUID: GPU-1a2b3c4d-...)".
+var nvidiaSMIListPattern = regexp.MustCompile(`^GPU (\d+):.*\(UUID: (GPU-[0-9a-fA-F-]+)\)`)
+
+// gpuUUIDs shells out to `nvidia-smi -L` and returns every GPU on the node,
+// keyed by its index, so VisibleDevices selections (which may name either
+// indices or UUIDs) can be resolved to UUIDs.
+func gpuUUIDs() (map[string]string, error) {
+	out, err := exec.Command("nvidia-smi", "-L").Output()
+	if err != nil {
+		return nil, fmt.Errorf("nvidia-smi -L: %w", err)
+	}
+
+	uuids := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		m := nvidiaSMIListPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		uuids[m[1]] = m[2]
+	}
+	return uuids, nil
+}
+
+// resolveDeviceIDs turns a container's NVIDIA_VISIBLE_DEVICES value ("all",
+// a comma list of indices, or a comma list of UUIDs already) into concrete
+// GPU UUIDs.
+func resolveDeviceIDs(visible string) ([]string, error) {
+	uuids, err := gpuUUIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	if visible == "" || visible == "all" {
+		ids := make([]string, 0, len(uuids))
+		for i := 0; i < len(uuids); i++ {
+			if uuid, ok := uuids[strconv.Itoa(i)]; ok {
+				ids = append(ids, uuid)
+			}
+		}
+		return ids, nil
+	}
+
+	var ids []string
+	for _, tok := range strings.Split(visible, ",") {
+		tok = strings.TrimSpace(tok)
+		if strings.HasPrefix(tok, "GPU-") {
+			ids = append(ids, tok)
+			continue
+		}
+		if uuid, ok := uuids[tok]; ok {
+			ids = append(ids, uuid)
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,122 @@
+// Package podresources implements the kubelet PodResourcesLister gRPC API
+// (k8s.io/kubelet/pkg/apis/podresources/v1) against this provider's pod
+// registry, so device-plugin-aware workloads (the NVIDIA GPU operator, RDMA
+// and DPDK sidecars) can discover which devices and CPUs are bound to which
+// Apptainer container the same way they would against a real kubelet.
+package podresources
+
+import (
+	"context"
+	"fmt"
+
+	podresourcesapi "k8s.io/kubelet/pkg/apis/podresources/v1"
+)
+
+// PodSource is how the server learns which pods/containers to report on,
+// without importing the provider package (which imports this one for
+// PodRef and ContainerRef).
+type PodSource interface {
+	PodResources() []PodRef
+}
+
+// PodRef is the podresources server's view of a single pod.
+type PodRef struct {
+	Namespace  string
+	Name       string
+	UID        string
+	Containers []ContainerRef
+}
+
+// ContainerRef is the podresources server's view of a single container.
+// VisibleDevices carries the container's NVIDIA_VISIBLE_DEVICES value
+// ("all", a comma list of indices, or a comma list of UUIDs) so the server
+// can resolve it to concrete device IDs at scrape time; it is only
+// meaningful when GPU is set.
+type ContainerRef struct {
+	Name           string
+	GPU            bool
+	VisibleDevices string
+}
+
+// Server implements podresourcesapi.PodResourcesListerServer.
+type Server struct {
+	podresourcesapi.UnimplementedPodResourcesListerServer
+
+	podSource PodSource
+}
+
+// NewServer returns a PodResourcesListerServer backed by podSource.
+func NewServer(podSource PodSource) *Server {
+	return &Server{podSource: podSource}
+}
+
+// List implements the List RPC, reporting every pod/container the provider
+// currently has registered along with the devices and CPUs bound to it.
+func (s *Server) List(ctx context.Context, _ *podresourcesapi.ListPodResourcesRequest) (*podresourcesapi.ListPodResourcesResponse, error) {
+	var resp podresourcesapi.ListPodResourcesResponse
+	for _, pod := range s.podSource.PodResources() {
+		resp.PodResources = append(resp.PodResources, podResourcesFor(pod))
+	}
+	return &resp, nil
+}
+
+// Get implements the Get RPC, reporting a single pod by name.
+func (s *Server) Get(ctx context.Context, req *podresourcesapi.GetPodResourcesRequest) (*podresourcesapi.GetPodResourcesResponse, error) {
+	for _, pod := range s.podSource.PodResources() {
+		if pod.Namespace == req.PodNamespace && pod.Name == req.PodName {
+			return &podresourcesapi.GetPodResourcesResponse{PodResources: podResourcesFor(pod)}, nil
+		}
+	}
+	return nil, fmt.Errorf("pod %s/%s not found", req.PodNamespace, req.PodName)
+}
+
+// GetAllocatableResources implements the GetAllocatableResources RPC,
+// reporting every CPU and GPU the node has, regardless of current
+// assignment.
+func (s *Server) GetAllocatableResources(ctx context.Context, _ *podresourcesapi.AllocatableResourcesRequest) (*podresourcesapi.AllocatableResourcesResponse, error) {
+	cpuIDs, err := allocatableCPUIDs()
+	if err != nil {
+		return nil, fmt.Errorf("listing allocatable cpus: %w", err)
+	}
+
+	resp := &podresourcesapi.AllocatableResourcesResponse{CpuIds: cpuIDs}
+	if uuids, err := gpuUUIDs(); err == nil && len(uuids) > 0 {
+		ids := make([]string, 0, len(uuids))
+		for _, uuid := range uuids {
+			ids = append(ids, uuid)
+		}
+		resp.Devices = []*podresourcesapi.ContainerDevices{{
+			ResourceName: gpuResourceName,
+			DeviceIds:    ids,
+		}}
+	}
+	return resp, nil
+}
+
+func podResourcesFor(pod PodRef) *podresourcesapi.PodResources {
+	pr := &podresourcesapi.PodResources{
+		Name:      pod.Name,
+		Namespace: pod.Namespace,
+	}
+
+	for _, c := range pod.Containers {
+		cr := &podresourcesapi.ContainerResources{Name: c.Name}
+
+		if cpuIDs, err := containerCPUIDs(pod.Namespace, pod.Name, c.Name); err == nil {
+			cr.CpuIds = cpuIDs
+		}
+
+		if c.GPU {
+			if ids, err := resolveDeviceIDs(c.VisibleDevices); err == nil && len(ids) > 0 {
+				cr.Devices = append(cr.Devices, &podresourcesapi.ContainerDevices{
+					ResourceName: gpuResourceName,
+					DeviceIds:    ids,
+				})
+			}
+		}
+
+		pr.Containers = append(pr.Containers, cr)
+	}
+
+	return pr
+}
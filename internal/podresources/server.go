@@ -0,0 +1,42 @@
+package podresources
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+
+	"google.golang.org/grpc"
+	podresourcesapi "k8s.io/kubelet/pkg/apis/podresources/v1"
+)
+
+// Serve listens on socketPath and serves the PodResourcesLister API until
+// ctx is cancelled. socketPath's parent directory is created if needed, and
+// any stale socket left behind by a previous run is removed first.
+func Serve(ctx context.Context, socketPath string, s *Server) error {
+	if err := os.MkdirAll(filepath.Dir(socketPath), 0o755); err != nil {
+		return fmt.Errorf("creating pod resources socket dir: %w", err)
+	}
+	if err := os.RemoveAll(socketPath); err != nil {
+		return fmt.Errorf("removing stale pod resources socket: %w", err)
+	}
+
+	lis, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("listening on pod resources socket %s: %w", socketPath, err)
+	}
+
+	grpcServer := grpc.NewServer()
+	podresourcesapi.RegisterPodResourcesListerServer(grpcServer, s)
+
+	go func() {
+		<-ctx.Done()
+		grpcServer.GracefulStop()
+	}()
+
+	if err := grpcServer.Serve(lis); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("serving pod resources socket: %w", err)
+	}
+	return nil
+}
@@ -2,15 +2,25 @@ package provider
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"sync"
+	"time"
 
 	"github.com/batx-dev/virtual-kubelet-apptainer/internal/metrics"
+	"github.com/batx-dev/virtual-kubelet-apptainer/internal/readiness"
+	"github.com/virtual-kubelet/virtual-kubelet/log"
 	"github.com/virtual-kubelet/virtual-kubelet/node/api"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
 	gpuResourceName = "nvidia.com/gpu"
+
+	// pollInterval is how often we reconcile pod/container status against
+	// `apptainer instance list`.
+	pollInterval = 5 * time.Second
 )
 
 type ApptainerProvider struct {
@@ -23,41 +33,290 @@ type ApptainerProvider struct {
 	internalIP         string
 	daemonEndpointPort int32
 
+	registry       *registry
+	runtimes       map[string]Runtime
+	defaultRuntime string
+	slurmPartition string
+	readiness      *readiness.Tracker
+
+	notifyMu sync.RWMutex
+	notify   func(*corev1.Pod)
+
 	*metrics.ApptaienrMetricsProvider
 }
 
 func NewApptainerProvider(ctx context.Context, nodeName, operatingSystem, internalIP string,
-	daemonEndpointPort int32) (*ApptainerProvider, error) {
+	daemonEndpointPort int32, runtimeCfg RuntimeConfig, readinessCfg readiness.Config) (*ApptainerProvider, error) {
 	p := &ApptainerProvider{}
 
 	p.nodeName = nodeName
 	p.operatingSystem = operatingSystem
 	p.internalIP = internalIP
 	p.daemonEndpointPort = daemonEndpointPort
+	p.runtimes = newRuntimes(ctx, runtimeCfg)
+	p.defaultRuntime = runtimeCfg.Default
+	if p.defaultRuntime == "" {
+		p.defaultRuntime = RuntimeLocal
+	}
+	p.slurmPartition = runtimeCfg.SlurmPartition
+	p.readiness = readiness.NewTracker(p, readinessCfg)
 
 	if err := p.setupNodeCapacity(ctx); err != nil {
 		return nil, err
 	}
 
-	p.ApptaienrMetricsProvider = metrics.NewApptaienrMetricsProver()
+	reg, err := newRegistry()
+	if err != nil {
+		return nil, fmt.Errorf("loading pod registry: %w", err)
+	}
+	p.registry = reg
+
+	p.ApptaienrMetricsProvider = metrics.NewApptaienrMetricsProver(nodeName, p)
+
+	go p.reconcileLoop(ctx)
+
 	return p, nil
 }
 
+// runtimeFor resolves the Runtime a pod should use, falling back to the
+// provider's default when neither the pod nor the registry records one.
+func (p *ApptainerProvider) runtimeFor(kind string) Runtime {
+	if kind == "" {
+		kind = p.defaultRuntime
+	}
+	if rt, ok := p.runtimes[kind]; ok {
+		return rt
+	}
+	return p.runtimes[RuntimeLocal]
+}
+
+// NotifyPods registers the callback the virtual-kubelet core uses to learn
+// about pod status changes outside of its own polling. The provider calls it
+// from reconcileLoop whenever an Apptainer instance transitions state.
+func (p *ApptainerProvider) NotifyPods(ctx context.Context, notifier func(*corev1.Pod)) {
+	p.notifyMu.Lock()
+	p.notify = notifier
+	p.notifyMu.Unlock()
+}
+
+// notifyPod calls the registered NotifyPods callback, if any. notify is set
+// by NotifyPods on one goroutine and read here from reconcileLoop and the
+// pod lifecycle methods, so it needs its own lock rather than the record
+// lock, which guards pod state, not the provider itself.
+func (p *ApptainerProvider) notifyPod(pod *corev1.Pod) {
+	p.notifyMu.RLock()
+	notify := p.notify
+	p.notifyMu.RUnlock()
+	if notify != nil {
+		notify(pod)
+	}
+}
+
+// reconcileLoop polls `apptainer instance list` and updates every registered
+// pod's status accordingly, notifying the virtual-kubelet core of changes.
+// It also runs once at startup, which is what lets a restarted
+// virtual-kubelet process pick back up instances an earlier process started
+// rather than losing track of them.
+func (p *ApptainerProvider) reconcileLoop(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	p.reconcileOnce(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.reconcileOnce(ctx)
+		}
+	}
+}
+
+func (p *ApptainerProvider) reconcileOnce(ctx context.Context) {
+	for _, rec := range p.registry.list() {
+		rec.mu.RLock()
+		runtimeKind, jobID := rec.RuntimeKind, rec.JobID
+		namespace, name := rec.Pod.Namespace, rec.Pod.Name
+		rec.mu.RUnlock()
+
+		rs, err := p.runtimeFor(runtimeKind).Status(ctx, jobID)
+		if err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to get status of job %s for pod %s/%s", jobID, namespace, name)
+			continue
+		}
+
+		// Held only for this in-memory bookkeeping, not the probes/restarts
+		// below: those shell out and can take seconds, and GetPod/
+		// GetPodStatus/GetPods/ListPods/PodResources only take the record's
+		// read lock, so holding the write lock across a shell-out would
+		// block them for the duration of every poll.
+		rec.mu.Lock()
+		changed := refreshPodStatus(rec, rs)
+		if changed {
+			applySchedulerBackendTermination(rec)
+		}
+		rec.mu.Unlock()
+
+		p.applyLiveness(ctx, rec)
+		if p.applyReadiness(ctx, rec) {
+			changed = true
+		}
+
+		var notifyPod *corev1.Pod
+		if changed {
+			rec.mu.RLock()
+			notifyPod = rec.Pod.DeepCopy()
+			rec.mu.RUnlock()
+		}
+
+		if changed {
+			if err := p.registry.put(namespace, name, rec); err != nil {
+				log.G(ctx).WithError(err).Warn("failed to persist pod registry")
+			}
+			p.notifyPod(notifyPod)
+		}
+	}
+}
+
 // CreatePod takes a Kubernetes Pod and deploys it within the provider.
 func (p *ApptainerProvider) CreatePod(ctx context.Context, pod *corev1.Pod) error {
+	runtimeKind := runtimeNameFor(pod, p.defaultRuntime)
+
+	for i := range pod.Spec.Containers {
+		c := &pod.Spec.Containers[i]
+		if err := prepareLogDir(pod.Namespace, pod.Name, c.Name); err != nil {
+			return fmt.Errorf("preparing log directory for container %s: %w", c.Name, err)
+		}
+	}
+	if err := prepareEmptyDirs(pod); err != nil {
+		return err
+	}
+
+	jobID, err := p.runtimeFor(runtimeKind).Submit(ctx, pod)
+	if err != nil {
+		return fmt.Errorf("submitting pod to %s runtime: %w", runtimeKind, err)
+	}
+
+	rec := &podRecord{
+		Pod:         pod.DeepCopy(),
+		RuntimeKind: runtimeKind,
+		JobID:       jobID,
+		Containers:  make(map[string]*containerRecord, len(pod.Spec.Containers)),
+	}
+	for i := range pod.Spec.Containers {
+		c := &pod.Spec.Containers[i]
+		rec.Containers[c.Name] = &containerRecord{
+			Name:      c.Name,
+			LogPath:   logDir(pod.Namespace, pod.Name, c.Name),
+			StartedAt: time.Now(),
+		}
+	}
+
+	rec.Pod.Status.Phase = corev1.PodPending
+	if err := p.registry.put(pod.Namespace, pod.Name, rec); err != nil {
+		return fmt.Errorf("persisting pod registry: %w", err)
+	}
 	return nil
 }
 
 // UpdatePod takes a Kubernetes Pod and updates it within the provider.
+//
+// Neither runtime supports reconfiguring a running job in place, so an
+// update is implemented as cancelling the old job and resubmitting the new
+// spec, carrying the previous restart counts forward.
 func (p *ApptainerProvider) UpdatePod(ctx context.Context, pod *corev1.Pod) error {
-	return nil
+	rec, ok := p.registry.get(pod.Namespace, pod.Name)
+	if !ok {
+		return p.CreatePod(ctx, pod)
+	}
+
+	rec.mu.RLock()
+	runtimeKindToCancel, jobIDToCancel := rec.RuntimeKind, rec.JobID
+	rec.mu.RUnlock()
+
+	if err := p.runtimeFor(runtimeKindToCancel).Cancel(ctx, jobIDToCancel); err != nil {
+		log.G(ctx).WithError(err).Warnf("failed to cancel previous job %s for pod %s/%s", jobIDToCancel, pod.Namespace, pod.Name)
+	}
+
+	runtimeKind := runtimeNameFor(pod, p.defaultRuntime)
+	for i := range pod.Spec.Containers {
+		c := &pod.Spec.Containers[i]
+		if err := prepareLogDir(pod.Namespace, pod.Name, c.Name); err != nil {
+			return fmt.Errorf("preparing log directory for container %s: %w", c.Name, err)
+		}
+	}
+	if err := prepareEmptyDirs(pod); err != nil {
+		return err
+	}
+
+	jobID, err := p.runtimeFor(runtimeKind).Submit(ctx, pod)
+	if err != nil {
+		return fmt.Errorf("resubmitting pod to %s runtime: %w", runtimeKind, err)
+	}
+
+	rec.mu.Lock()
+	containers := make(map[string]*containerRecord, len(pod.Spec.Containers))
+	for i := range pod.Spec.Containers {
+		c := &pod.Spec.Containers[i]
+		restartCount := int32(0)
+		if old, ok := rec.Containers[c.Name]; ok {
+			restartCount = old.RestartCount + 1
+		}
+		containers[c.Name] = &containerRecord{
+			Name:         c.Name,
+			LogPath:      logDir(pod.Namespace, pod.Name, c.Name),
+			RestartCount: restartCount,
+			StartedAt:    time.Now(),
+		}
+	}
+
+	rec.Pod = pod.DeepCopy()
+	rec.RuntimeKind = runtimeKind
+	rec.JobID = jobID
+	rec.Containers = containers
+	rec.mu.Unlock()
+
+	return p.registry.put(pod.Namespace, pod.Name, rec)
 }
 
 // DeletePod takes a Kubernetes Pod and deletes it from the provider. Once a pod is deleted, the provider is
 // expected to call the NotifyPods callback with a terminal pod status where all the containers are in a terminal
 // state, as well as the pod. DeletePod may be called multiple times for the same pod.
 func (p *ApptainerProvider) DeletePod(ctx context.Context, pod *corev1.Pod) error {
-	return nil
+	rec, ok := p.registry.get(pod.Namespace, pod.Name)
+	if !ok {
+		return nil
+	}
+
+	rec.mu.RLock()
+	runtimeKind, jobID := rec.RuntimeKind, rec.JobID
+	rec.mu.RUnlock()
+
+	if err := p.runtimeFor(runtimeKind).Cancel(ctx, jobID); err != nil {
+		log.G(ctx).WithError(err).Warnf("failed to cancel job %s for pod %s/%s", jobID, pod.Namespace, pod.Name)
+	}
+
+	rec.mu.Lock()
+	now := metav1.Now()
+	for i := range rec.Pod.Status.ContainerStatuses {
+		rec.Pod.Status.ContainerStatuses[i].State = corev1.ContainerState{
+			Terminated: &corev1.ContainerStateTerminated{
+				ExitCode:   0,
+				Reason:     "Completed",
+				FinishedAt: now,
+			},
+		}
+	}
+	rec.Pod.Status.Phase = corev1.PodSucceeded
+
+	reason, message := deletionDisruptionReason(pod)
+	setDisruptionCondition(&rec.Pod.Status, reason, message)
+	notifyPod := rec.Pod.DeepCopy()
+	rec.mu.Unlock()
+
+	p.notifyPod(notifyPod)
+
+	return p.registry.delete(pod.Namespace, pod.Name)
 }
 
 // GetPod retrieves a pod by name from the provider (can be cached).
@@ -65,7 +324,13 @@ func (p *ApptainerProvider) DeletePod(ctx context.Context, pod *corev1.Pod) erro
 // concurrently outside of the calling goroutine. Therefore it is recommended
 // to return a version after DeepCopy.
 func (p *ApptainerProvider) GetPod(ctx context.Context, namespace, name string) (*corev1.Pod, error) {
-	return nil, nil
+	rec, ok := p.registry.get(namespace, name)
+	if !ok {
+		return nil, nil
+	}
+	rec.mu.RLock()
+	defer rec.mu.RUnlock()
+	return rec.Pod.DeepCopy(), nil
 }
 
 // GetPodStatus retrieves the status of a pod by name from the provider.
@@ -73,7 +338,13 @@ func (p *ApptainerProvider) GetPod(ctx context.Context, namespace, name string)
 // concurrently outside of the calling goroutine. Therefore it is recommended
 // to return a version after DeepCopy.
 func (p *ApptainerProvider) GetPodStatus(ctx context.Context, namespace, name string) (*corev1.PodStatus, error) {
-	return nil, nil
+	rec, ok := p.registry.get(namespace, name)
+	if !ok {
+		return nil, nil
+	}
+	rec.mu.RLock()
+	defer rec.mu.RUnlock()
+	return rec.Pod.Status.DeepCopy(), nil
 }
 
 // GetPods retrieves a list of all pods running on the provider (can be cached).
@@ -81,16 +352,74 @@ func (p *ApptainerProvider) GetPodStatus(ctx context.Context, namespace, name st
 // concurrently outside of the calling goroutine. Therefore it is recommended
 // to return a version after DeepCopy.
 func (p *ApptainerProvider) GetPods(context.Context) ([]*corev1.Pod, error) {
-	return nil, nil
+	recs := p.registry.list()
+	pods := make([]*corev1.Pod, 0, len(recs))
+	for _, rec := range recs {
+		rec.mu.RLock()
+		pods = append(pods, rec.Pod.DeepCopy())
+		rec.mu.RUnlock()
+	}
+	return pods, nil
+}
+
+// ListPods implements metrics.PodSource, giving the stats collector the set
+// of pods/containers it should gather cgroup and /proc metrics for.
+func (p *ApptainerProvider) ListPods() []metrics.PodRef {
+	recs := p.registry.list()
+	refs := make([]metrics.PodRef, 0, len(recs))
+	for _, rec := range recs {
+		rec.mu.RLock()
+		ref := metrics.PodRef{
+			Namespace: rec.Pod.Namespace,
+			Name:      rec.Pod.Name,
+			UID:       string(rec.Pod.UID),
+			StartTime: rec.Pod.CreationTimestamp.Time,
+		}
+		for _, c := range rec.Pod.Spec.Containers {
+			cr, ok := rec.Containers[c.Name]
+			if !ok {
+				continue
+			}
+			_, gpu := c.Resources.Limits[gpuResourceName]
+			ref.Containers = append(ref.Containers, metrics.ContainerRef{
+				Name:      c.Name,
+				StartTime: cr.StartedAt,
+				GPU:       gpu,
+			})
+		}
+		rec.mu.RUnlock()
+		refs = append(refs, ref)
+	}
+	return refs
 }
 
 // GetContainerLogs retrieves the logs of a container by name from the provider.
 func (p *ApptainerProvider) GetContainerLogs(ctx context.Context, namespace, podName, containerName string, opts api.ContainerLogOpts) (io.ReadCloser, error) {
-	return nil, nil
+	rec, ok := p.registry.get(namespace, podName)
+	if !ok {
+		return nil, fmt.Errorf("pod %s/%s not found", namespace, podName)
+	}
+	rec.mu.RLock()
+	runtimeKind, jobID := rec.RuntimeKind, rec.JobID
+	rec.mu.RUnlock()
+
+	path, err := p.runtimeFor(runtimeKind).Logs(ctx, jobID, containerName)
+	if err != nil {
+		return nil, err
+	}
+	return readContainerLogs(ctx, path, opts)
 }
 
 // RunInContainer executes a command in a container in the pod, copying data
 // between in/out/err and the container's stdin/stdout/stderr.
 func (p *ApptainerProvider) RunInContainer(ctx context.Context, namespace, podName, containerName string, cmd []string, attach api.AttachIO) error {
-	return nil
+	rec, ok := p.registry.get(namespace, podName)
+	if !ok {
+		return fmt.Errorf("pod %s/%s not found", namespace, podName)
+	}
+	rec.mu.RLock()
+	runtimeKind, jobID := rec.RuntimeKind, rec.JobID
+	rec.mu.RUnlock()
+
+	return p.runtimeFor(runtimeKind).Exec(ctx, jobID, containerName, cmd, attach)
 }
@@ -0,0 +1,85 @@
+package provider
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DisruptionReason is a machine-readable explanation for why a pod is being
+// torn down, matching the taxonomy upstream Kubernetes uses for its own
+// DisruptionTarget pod condition so that Jobs with a PodFailurePolicy and
+// the cluster autoscaler can react to it the same way they would on a node
+// running a real kubelet.
+type DisruptionReason string
+
+const (
+	// ReasonPreemptionByKubeScheduler is set by kube-apiserver when
+	// kube-scheduler displaces this pod with a higher-priority one, before
+	// DeletePod is ever called; the provider only preserves it.
+	ReasonPreemptionByKubeScheduler DisruptionReason = "PreemptionByKubeScheduler"
+	// ReasonEvictionByEvictionAPI is set by kube-apiserver when the pod is
+	// removed via the eviction subresource (kubectl drain, a PDB-aware
+	// controller); the provider only preserves it.
+	ReasonEvictionByEvictionAPI DisruptionReason = "EvictionByEvictionAPI"
+	// ReasonDeletionByPodGC is the fallback used when DeletePod is called
+	// with no more specific DisruptionTarget condition already present,
+	// which is also the reason the real pod garbage collector uses for an
+	// orphaned pod.
+	ReasonDeletionByPodGC DisruptionReason = "DeletionByPodGC"
+	// ReasonTerminationBySchedulerBackend is specific to this provider: the
+	// batch scheduler running underneath a pod (SLURM/PBS) killed the job
+	// itself - out of memory, over its walltime, or its node failed - with
+	// no Kubernetes-side deletion involved at all.
+	ReasonTerminationBySchedulerBackend DisruptionReason = "TerminationBySchedulerBackend"
+)
+
+// setDisruptionCondition sets (or replaces) the DisruptionTarget condition
+// on status, the way kube-apiserver does before a disrupted pod's terminal
+// status is observed by its controller.
+func setDisruptionCondition(status *corev1.PodStatus, reason DisruptionReason, message string) {
+	cond := corev1.PodCondition{
+		Type:               corev1.DisruptionTarget,
+		Status:             corev1.ConditionTrue,
+		Reason:             string(reason),
+		Message:            message,
+		LastTransitionTime: metav1.Now(),
+	}
+	for i, existing := range status.Conditions {
+		if existing.Type == corev1.DisruptionTarget {
+			status.Conditions[i] = cond
+			return
+		}
+	}
+	status.Conditions = append(status.Conditions, cond)
+}
+
+// deletionDisruptionReason classifies a DeletePod call. If kube-apiserver
+// already attached a DisruptionTarget condition for preemption or an
+// eviction subresource call before the deletion reached the provider, that
+// reason is preserved; otherwise this is treated as a plain delete, which is
+// also how the pod garbage collector's own deletions look from here.
+func deletionDisruptionReason(pod *corev1.Pod) (DisruptionReason, string) {
+	for _, c := range pod.Status.Conditions {
+		if c.Type != corev1.DisruptionTarget || c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch DisruptionReason(c.Reason) {
+		case ReasonPreemptionByKubeScheduler:
+			return ReasonPreemptionByKubeScheduler, c.Message
+		case ReasonEvictionByEvictionAPI:
+			return ReasonEvictionByEvictionAPI, c.Message
+		}
+	}
+	return ReasonDeletionByPodGC, "pod deleted"
+}
+
+// applySchedulerBackendTermination sets ReasonTerminationBySchedulerBackend
+// on rec when reconcileOnce observes its SLURM job has failed on its
+// own, without ever going through DeletePod - the only disruption path
+// that's entirely this provider's to know about.
+func applySchedulerBackendTermination(rec *podRecord) {
+	if rec.RuntimeKind != RuntimeSlurm || rec.Pod.Status.Phase != corev1.PodFailed {
+		return
+	}
+	setDisruptionCondition(&rec.Pod.Status, ReasonTerminationBySchedulerBackend, "slurm job terminated outside of a pod deletion")
+}
@@ -0,0 +1,123 @@
+package provider
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestSetDisruptionCondition(t *testing.T) {
+	status := &corev1.PodStatus{}
+
+	setDisruptionCondition(status, ReasonDeletionByPodGC, "first")
+	if got := len(status.Conditions); got != 1 {
+		t.Fatalf("expected 1 condition, got %d", got)
+	}
+	if got := status.Conditions[0].Reason; got != string(ReasonDeletionByPodGC) {
+		t.Fatalf("expected reason %q, got %q", ReasonDeletionByPodGC, got)
+	}
+
+	// A second call for the same pod must replace, not append.
+	setDisruptionCondition(status, ReasonTerminationBySchedulerBackend, "second")
+	if got := len(status.Conditions); got != 1 {
+		t.Fatalf("expected condition to be replaced in place, got %d conditions", got)
+	}
+	if got := status.Conditions[0].Reason; got != string(ReasonTerminationBySchedulerBackend) {
+		t.Fatalf("expected reason %q, got %q", ReasonTerminationBySchedulerBackend, got)
+	}
+	if got := status.Conditions[0].Message; got != "second" {
+		t.Fatalf("expected message %q, got %q", "second", got)
+	}
+}
+
+func TestDeletionDisruptionReason(t *testing.T) {
+	cases := []struct {
+		name       string
+		conditions []corev1.PodCondition
+		want       DisruptionReason
+	}{
+		{
+			name:       "plain delete falls back to pod gc",
+			conditions: nil,
+			want:       ReasonDeletionByPodGC,
+		},
+		{
+			name: "preserves preemption set upstream",
+			conditions: []corev1.PodCondition{{
+				Type:   corev1.DisruptionTarget,
+				Status: corev1.ConditionTrue,
+				Reason: string(ReasonPreemptionByKubeScheduler),
+			}},
+			want: ReasonPreemptionByKubeScheduler,
+		},
+		{
+			name: "preserves eviction api set upstream",
+			conditions: []corev1.PodCondition{{
+				Type:   corev1.DisruptionTarget,
+				Status: corev1.ConditionTrue,
+				Reason: string(ReasonEvictionByEvictionAPI),
+			}},
+			want: ReasonEvictionByEvictionAPI,
+		},
+		{
+			name: "ignores a condition that isn't True",
+			conditions: []corev1.PodCondition{{
+				Type:   corev1.DisruptionTarget,
+				Status: corev1.ConditionFalse,
+				Reason: string(ReasonPreemptionByKubeScheduler),
+			}},
+			want: ReasonDeletionByPodGC,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pod := &corev1.Pod{Status: corev1.PodStatus{Conditions: tc.conditions}}
+			got, _ := deletionDisruptionReason(pod)
+			if got != tc.want {
+				t.Fatalf("expected reason %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestApplySchedulerBackendTermination(t *testing.T) {
+	t.Run("slurm job that failed on its own gets the scheduler-backend reason", func(t *testing.T) {
+		rec := &podRecord{
+			RuntimeKind: RuntimeSlurm,
+			Pod:         &corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodFailed}},
+		}
+		applySchedulerBackendTermination(rec)
+
+		if got := len(rec.Pod.Status.Conditions); got != 1 {
+			t.Fatalf("expected 1 condition, got %d", got)
+		}
+		if got := rec.Pod.Status.Conditions[0].Reason; got != string(ReasonTerminationBySchedulerBackend) {
+			t.Fatalf("expected reason %q, got %q", ReasonTerminationBySchedulerBackend, got)
+		}
+	})
+
+	t.Run("local runtime is never attributed to the scheduler backend", func(t *testing.T) {
+		rec := &podRecord{
+			RuntimeKind: RuntimeLocal,
+			Pod:         &corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodFailed}},
+		}
+		applySchedulerBackendTermination(rec)
+
+		if got := len(rec.Pod.Status.Conditions); got != 0 {
+			t.Fatalf("expected no condition set for the local runtime, got %d", got)
+		}
+	})
+
+	t.Run("a running slurm job is left alone", func(t *testing.T) {
+		rec := &podRecord{
+			RuntimeKind: RuntimeSlurm,
+			Pod:         &corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodRunning}},
+		}
+		applySchedulerBackendTermination(rec)
+
+		if got := len(rec.Pod.Status.Conditions); got != 0 {
+			t.Fatalf("expected no condition set for a running job, got %d", got)
+		}
+	})
+}
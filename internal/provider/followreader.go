@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+)
+
+// followReader reads path the way `tail -F` does: it blocks for new data
+// appended to the file and transparently reopens path if it shrinks or its
+// inode changes (log rotation), rather than returning EOF for good.
+type followReader struct {
+	ctx    context.Context
+	path   string
+	f      *os.File
+	pos    int64
+	opened bool
+}
+
+// newFollowReader starts following path from startPos, so a caller that has
+// already consumed everything up to a known offset (e.g. the lines
+// readContainerLogs already sent before switching to follow mode) doesn't
+// see it again.
+func newFollowReader(ctx context.Context, path string, startPos int64) *followReader {
+	return &followReader{ctx: ctx, path: path, pos: startPos}
+}
+
+func (r *followReader) Read(p []byte) (int, error) {
+	for {
+		if err := r.ctx.Err(); err != nil {
+			return 0, io.EOF
+		}
+
+		if r.f == nil {
+			f, err := os.Open(r.path)
+			if err != nil {
+				time.Sleep(500 * time.Millisecond)
+				continue
+			}
+			r.f = f
+			// The starting position only applies to the very first open;
+			// a reopen means the file was truncated or rotated out from
+			// under us, so the replacement starts fresh from byte 0.
+			if r.opened {
+				r.pos = 0
+			}
+			r.opened = true
+		}
+
+		n, err := r.f.ReadAt(p, r.pos)
+		if n > 0 {
+			r.pos += int64(n)
+			return n, nil
+		}
+		if err != nil && err != io.EOF {
+			r.f.Close()
+			r.f = nil
+			continue
+		}
+
+		fi, statErr := os.Stat(r.path)
+		cur, curErr := r.f.Stat()
+		if statErr == nil && curErr == nil && (fi.Size() < r.pos || !os.SameFile(cur, fi)) {
+			// Truncated or rotated out from under us; reopen from the start.
+			r.f.Close()
+			r.f = nil
+			continue
+		}
+
+		// No new data yet; block like `tail -f` rather than returning EOF.
+		time.Sleep(200 * time.Millisecond)
+	}
+}
+
+func (r *followReader) Close() error {
+	if r.f != nil {
+		return r.f.Close()
+	}
+	return nil
+}
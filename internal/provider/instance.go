@@ -0,0 +1,305 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"github.com/creack/pty"
+	"github.com/mitchellh/go-homedir"
+	"github.com/virtual-kubelet/virtual-kubelet/node/api"
+	"golang.org/x/sys/unix"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// instanceName derives the Apptainer instance name for a single container of
+// a pod. It intentionally excludes the pod UID so that the name matches the
+// log directory layout (see logDir), at the cost of a brief collision window
+// across a delete/recreate of the same namespace/pod/container triple; that
+// window is closed by DeletePod stopping the old instance before returning.
+func instanceName(namespace, podName, containerName string) string {
+	return fmt.Sprintf("%s_%s_%s", namespace, podName, containerName)
+}
+
+// imageURI maps a Kubernetes container image reference onto an Apptainer
+// image URI. References that are already in a form Apptainer understands
+// (docker://, oras://, library://, or a path to a .sif) are passed through
+// unchanged; anything else is assumed to be a bare registry reference and
+// gets a docker:// prefix, which is how Apptainer pulls OCI images.
+func imageURI(image string) string {
+	switch {
+	case strings.Contains(image, "://"):
+		return image
+	case strings.HasSuffix(image, ".sif"):
+		return image
+	default:
+		return "docker://" + image
+	}
+}
+
+// bindArgs translates a container's VolumeMounts into `--bind` flags for
+// `apptainer instance start`. Only volume types that resolve to a host path
+// are supported; VolumeMounts referencing anything else are skipped since
+// Apptainer has no concept of a Kubernetes-managed volume.
+func bindArgs(pod *corev1.Pod, c *corev1.Container) []string {
+	volumes := make(map[string]*corev1.Volume, len(pod.Spec.Volumes))
+	for i := range pod.Spec.Volumes {
+		volumes[pod.Spec.Volumes[i].Name] = &pod.Spec.Volumes[i]
+	}
+
+	var args []string
+	for _, mount := range c.VolumeMounts {
+		vol, ok := volumes[mount.Name]
+		if !ok {
+			continue
+		}
+
+		var hostPath string
+		switch {
+		case vol.HostPath != nil:
+			hostPath = vol.HostPath.Path
+		case vol.EmptyDir != nil:
+			hostPath = emptyDirPath(pod, mount.Name)
+		default:
+			continue
+		}
+
+		bind := fmt.Sprintf("%s:%s", hostPath, mount.MountPath)
+		if mount.ReadOnly {
+			bind += ":ro"
+		}
+		args = append(args, "--bind", bind)
+	}
+	return args
+}
+
+// emptyDirPath returns a per-pod, per-volume scratch directory used to back
+// an emptyDir volume, created under the same state tree as the pod registry.
+func emptyDirPath(pod *corev1.Pod, volumeName string) string {
+	stateHome := os.Getenv("XDG_STATE_HOME")
+	if stateHome == "" {
+		stateHome = "/tmp"
+		if home, err := homedir.Dir(); err == nil {
+			stateHome = filepath.Join(home, ".local", "state")
+		}
+	}
+	return fmt.Sprintf("%s/vk-apptainer/emptydir/%s_%s_%s", stateHome, pod.Namespace, pod.Name, volumeName)
+}
+
+// prepareEmptyDirs creates the scratch directory backing every emptyDir
+// volume in pod, the way prepareLogDir does for a container's log directory.
+// bindArgs only ever reads this path; nothing else creates it, so a pod with
+// an emptyDir volume would otherwise fail to start with apptainer refusing
+// to bind a directory that doesn't exist.
+func prepareEmptyDirs(pod *corev1.Pod) error {
+	for _, vol := range pod.Spec.Volumes {
+		if vol.EmptyDir == nil {
+			continue
+		}
+		if err := os.MkdirAll(emptyDirPath(pod, vol.Name), 0o755); err != nil {
+			return fmt.Errorf("creating emptyDir volume %s: %w", vol.Name, err)
+		}
+	}
+	return nil
+}
+
+// gpuArgs returns the --nv flag when the container has requested GPUs via
+// the nvidia.com/gpu resource, enabling Apptainer's Nvidia GPU support.
+func gpuArgs(c *corev1.Container) []string {
+	if qty, ok := c.Resources.Limits[gpuResourceName]; ok && !qty.IsZero() {
+		return []string{"--nv"}
+	}
+	return nil
+}
+
+// securityArgs maps a container's SecurityContext onto the closest
+// equivalent Apptainer flags: a container that runs as a non-root UID gets
+// its own user namespace, and one that asks to run as root inside the
+// container is given --fakeroot rather than actually running privileged.
+func securityArgs(c *corev1.Container) []string {
+	sc := c.SecurityContext
+	if sc == nil {
+		return nil
+	}
+
+	var args []string
+	if sc.RunAsUser != nil && *sc.RunAsUser != 0 {
+		args = append(args, "--userns")
+	}
+	if sc.RunAsUser != nil && *sc.RunAsUser == 0 {
+		args = append(args, "--fakeroot")
+	}
+	return args
+}
+
+// envArgs translates a container's environment variables into
+// APPTAINERENV_* entries, which is how Apptainer passes environment into
+// the instance.
+func envArgs(c *corev1.Container) []string {
+	env := make([]string, 0, len(c.Env))
+	for _, e := range c.Env {
+		env = append(env, fmt.Sprintf("APPTAINERENV_%s=%s", e.Name, e.Value))
+	}
+	return env
+}
+
+// exitCodeMountPath is where a container's log directory (which already
+// exists by the time its instance starts, see prepareLogDir) is bound
+// inside the container so wrapCommandForExitCode has somewhere to leave the
+// real exit code once the command finishes.
+const exitCodeMountPath = "/var/run/vk-apptainer"
+
+// exitCodeFileName is the file wrapCommandForExitCode writes under
+// exitCodeMountPath, and what refreshPodStatus reads back from logDir on
+// the host side once the instance disappears from `instance list`.
+const exitCodeFileName = "exitcode"
+
+// exitCodePath is the host-side path to a container's exit code file,
+// written by the wrapper startInstanceArgs runs the container's command
+// under.
+func exitCodePath(namespace, podName, containerName string) string {
+	return filepath.Join(logDir(namespace, podName, containerName), exitCodeFileName)
+}
+
+// wrapCommandForExitCode runs c's command under a shell that captures its
+// real exit status into exitCodeFileName, rather than apptainer just
+// daemonizing it directly. `apptainer instance start` gives no way to query
+// an exited instance's exit code afterwards, so without this every instance
+// that disappears from `instance list` looks the same, whether it ran to
+// completion or crashed.
+func wrapCommandForExitCode(c *corev1.Container) []string {
+	cmd := append(append([]string{}, c.Command...), c.Args...)
+	script := fmt.Sprintf(`"$@"; rc=$?; echo "$rc" > %s/%s; exit "$rc"`, exitCodeMountPath, exitCodeFileName)
+	return append([]string{"/bin/sh", "-c", script, "sh"}, cmd...)
+}
+
+// startInstanceArgs builds the full `apptainer instance start` argument
+// list for a single container.
+func startInstanceArgs(pod *corev1.Pod, c *corev1.Container, instance string) []string {
+	args := []string{"instance", "start"}
+	args = append(args, gpuArgs(c)...)
+	args = append(args, securityArgs(c)...)
+	args = append(args, bindArgs(pod, c)...)
+	args = append(args, "--bind", fmt.Sprintf("%s:%s", logDir(pod.Namespace, pod.Name, c.Name), exitCodeMountPath))
+	args = append(args, imageURI(c.Image), instance)
+	args = append(args, wrapCommandForExitCode(c)...)
+	return args
+}
+
+// runApptainer runs `apptainer` with the given arguments and the container's
+// environment variables layered on top of the provider process's own
+// environment.
+func runApptainer(c *corev1.Container, args ...string) ([]byte, error) {
+	cmd := exec.Command("apptainer", args...)
+	if c != nil {
+		cmd.Env = append(os.Environ(), envArgs(c)...)
+	}
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return out, fmt.Errorf("apptainer %s: %w: %s", strings.Join(args, " "), err, string(out))
+	}
+	return out, nil
+}
+
+// logDir returns the directory GetContainerLogs/CreatePod use to store a
+// container's stdout/stderr.
+func logDir(namespace, podName, containerName string) string {
+	return fmt.Sprintf("/var/log/vk-apptainer/%s_%s_%s", namespace, podName, containerName)
+}
+
+// prepareLogDir ensures a container's log directory exists before its
+// instance is started.
+func prepareLogDir(namespace, podName, containerName string) error {
+	return os.MkdirAll(logDir(namespace, podName, containerName), 0o755)
+}
+
+// containerLogFile is the single rotating log file CreatePod redirects a
+// container's stdout/stderr into.
+func containerLogFile(namespace, podName, containerName string) string {
+	return filepath.Join(logDir(namespace, podName, containerName), "0.log")
+}
+
+// restartInstance stops and restarts the single Apptainer instance backing
+// container c of pod, the way a failed liveness probe is handled: since the
+// local runtime gives each container its own instance, restarting one
+// doesn't disturb its siblings.
+func restartInstance(pod *corev1.Pod, c *corev1.Container) error {
+	inst := instanceName(pod.Namespace, pod.Name, c.Name)
+	if _, err := runApptainer(nil, "instance", "stop", inst); err != nil {
+		return fmt.Errorf("stopping instance for restart: %w", err)
+	}
+	if _, err := runApptainer(c, startInstanceArgs(pod, c, inst)...); err != nil {
+		return fmt.Errorf("starting instance for restart: %w", err)
+	}
+	return nil
+}
+
+// execInInstance runs cmd inside an already-started Apptainer instance. When
+// attach.TTY() is set it allocates a pseudo-terminal and forwards resize
+// events from attach.Resize() to it; otherwise attach's streams are wired
+// directly to the child process.
+func execInInstance(ctx context.Context, instance string, cmd []string, attach api.AttachIO) error {
+	args := append([]string{"exec", fmt.Sprintf("instance://%s", instance)}, cmd...)
+	c := exec.CommandContext(ctx, "apptainer", args...)
+
+	if !attach.TTY() {
+		c.Stdin = attach.Stdin()
+		c.Stdout = attach.Stdout()
+		c.Stderr = attach.Stderr()
+		return c.Run()
+	}
+
+	f, err := pty.Start(c)
+	if err != nil {
+		return fmt.Errorf("starting apptainer exec under a pty: %w", err)
+	}
+	defer f.Close()
+
+	go func() {
+		for size := range attach.Resize() {
+			pty.Setsize(f, &pty.Winsize{Rows: size.Height, Cols: size.Width})
+		}
+	}()
+
+	go io.Copy(f, attach.Stdin())
+	_, copyErr := io.Copy(attach.Stdout(), f)
+	if copyErr != nil && !errors.Is(copyErr, unix.EIO) {
+		// A PTY master read returns EIO once the slave side is gone, which
+		// is how a normal exit is signalled; anything else is a real error.
+		return fmt.Errorf("copying from pty: %w", copyErr)
+	}
+
+	return c.Wait()
+}
+
+// instanceLogPaths returns the default stdout/stderr log files Apptainer
+// itself writes for instance, under its per-user state directory. These are
+// what startLogForwarder tails into the provider's own managed log file.
+func instanceLogPaths(instance string) []string {
+	home, err := homedir.Dir()
+	if err != nil {
+		return nil
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "localhost"
+	}
+
+	username := "root"
+	if u, err := user.Current(); err == nil {
+		username = u.Username
+	}
+
+	dir := filepath.Join(home, ".apptainer", "instances", "logs", hostname, username, instance)
+	return []string{
+		filepath.Join(dir, instance+".out"),
+		filepath.Join(dir, instance+".err"),
+	}
+}
@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/virtual-kubelet/virtual-kubelet/log"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// applyLiveness runs rec's liveness probes and restarts any container that
+// fails one.
+//
+// Restart is only implemented for the local runtime: there, each container
+// is its own Apptainer instance, so stopping and starting just that one is
+// well-defined. Under the slurm runtime all of a pod's containers share a
+// single batch job script (see sbatchScriptTemplate), so there's no
+// per-container restart primitive to hook into; a failing liveness probe
+// there is left for the whole-job failure path
+// (applySchedulerBackendTermination) to eventually catch instead.
+//
+// Probing and restarting both shell out and can take seconds; both run with
+// rec unlocked, and the write lock is only taken to record the resulting
+// restart count, the same tradeoff applyReadiness makes.
+func (p *ApptainerProvider) applyLiveness(ctx context.Context, rec *podRecord) {
+	rec.mu.RLock()
+	runtimeKind := rec.RuntimeKind
+	pod := rec.Pod
+	rec.mu.RUnlock()
+
+	if runtimeKind != RuntimeLocal {
+		return
+	}
+
+	running := make(map[string]bool, len(pod.Status.ContainerStatuses))
+	for _, cs := range pod.Status.ContainerStatuses {
+		running[cs.Name] = cs.State.Running != nil
+	}
+
+	for _, name := range p.readiness.EvaluateLiveness(ctx, pod, running) {
+		c := findContainer(pod, name)
+		if c == nil {
+			continue
+		}
+
+		log.G(ctx).Warnf("liveness probe failed for container %s of pod %s/%s, restarting its instance", name, pod.Namespace, pod.Name)
+		if err := restartInstance(pod, c); err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to restart container %s of pod %s/%s after a failed liveness probe", name, pod.Namespace, pod.Name)
+			continue
+		}
+
+		rec.mu.Lock()
+		if cr, ok := rec.Containers[name]; ok {
+			cr.RestartCount++
+			cr.StartedAt = time.Now()
+		}
+		rec.mu.Unlock()
+	}
+}
+
+func findContainer(pod *corev1.Pod, name string) *corev1.Container {
+	for i := range pod.Spec.Containers {
+		if pod.Spec.Containers[i].Name == name {
+			return &pod.Spec.Containers[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/virtual-kubelet/virtual-kubelet/node/api"
+)
+
+// logTimestampFormat is written as a prefix on every line appended to a
+// container's log file, both so GetContainerLogs can honor SinceSeconds/
+// SinceTime without a separate index and so api.ContainerLogOpts.Timestamps
+// can just mean "don't strip the prefix".
+const logTimestampFormat = time.RFC3339Nano
+
+// startLogForwarder tails the Apptainer instance's own stdout/stderr log
+// files (the ones it writes by default under its state directory) and
+// appends timestamp-prefixed copies of every line into dest, which is what
+// GetContainerLogs reads from. It runs until ctx is cancelled.
+func startLogForwarder(ctx context.Context, instance, dest string) {
+	for _, src := range instanceLogPaths(instance) {
+		go forwardLog(ctx, src, dest)
+	}
+}
+
+func forwardLog(ctx context.Context, src, dest string) {
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return
+	}
+	defer out.Close()
+
+	r := newFollowReader(ctx, src, 0)
+	defer r.Close()
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fmt.Fprintf(out, "%s %s\n", time.Now().Format(logTimestampFormat), scanner.Text())
+	}
+}
+
+// readContainerLogs turns the raw log file at path into the io.ReadCloser
+// GetContainerLogs returns, honoring Tail/SinceSeconds/SinceTime/Timestamps/
+// LimitBytes/Follow the same way the kubelet's own log handler does. ctx is
+// the request's own context, so the follow goroutine it starts stops as
+// soon as the client disconnects instead of running forever.
+//
+// With Follow, lines appended after the initial read are streamed exactly
+// as written to the file: their timestamp prefix is kept whatever
+// Timestamps says, and they are not counted against LimitBytes.
+func readContainerLogs(ctx context.Context, path string, opts api.ContainerLogOpts) (io.ReadCloser, error) {
+	lines, err := readLogLines(path, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if !opts.Follow {
+		return io.NopCloser(bytes.NewReader(lines)), nil
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.Write(lines)
+		followAndCopy(ctx, path, pw)
+	}()
+	return pr, nil
+}
+
+// readLogLines reads path, applies SinceSeconds/SinceTime filtering and
+// Tail, strips timestamps unless requested, and enforces LimitBytes.
+func readLogLines(path string, opts api.ContainerLogOpts) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening container log %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var since time.Time
+	if opts.SinceSeconds > 0 {
+		since = time.Now().Add(-time.Duration(opts.SinceSeconds) * time.Second)
+	} else if !opts.SinceTime.IsZero() {
+		since = opts.SinceTime
+	}
+
+	var all []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !since.IsZero() {
+			if ts, ok := parseLogTimestamp(line); ok && ts.Before(since) {
+				continue
+			}
+		}
+		if !opts.Timestamps {
+			line = stripLogTimestamp(line)
+		}
+		all = append(all, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading container log %s: %w", path, err)
+	}
+
+	if opts.Tail > 0 && len(all) > opts.Tail {
+		all = all[len(all)-opts.Tail:]
+	}
+
+	out := []byte(strings.Join(all, "\n"))
+	if len(out) > 0 {
+		out = append(out, '\n')
+	}
+	if opts.LimitBytes > 0 && len(out) > opts.LimitBytes {
+		out = out[:opts.LimitBytes]
+	}
+	return out, nil
+}
+
+func parseLogTimestamp(line string) (time.Time, bool) {
+	fields := strings.SplitN(line, " ", 2)
+	if len(fields) == 0 {
+		return time.Time{}, false
+	}
+	ts, err := time.Parse(logTimestampFormat, fields[0])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return ts, true
+}
+
+func stripLogTimestamp(line string) string {
+	fields := strings.SplitN(line, " ", 2)
+	if len(fields) != 2 {
+		return line
+	}
+	if _, err := time.Parse(logTimestampFormat, fields[0]); err != nil {
+		return line
+	}
+	return fields[1]
+}
+
+// followAndCopy streams lines appended to path after this point into w, the
+// way `tail -F` does: it keeps polling for growth and re-opens the file if
+// it's truncated or replaced (e.g. on log rotation). It's the caller's job
+// (readContainerLogs) to have already sent everything up to here; starting
+// the followReader at path's current size is what keeps this from
+// re-sending the whole file from the top.
+func followAndCopy(ctx context.Context, path string, w *io.PipeWriter) {
+	defer w.Close()
+
+	var startPos int64
+	if fi, err := os.Stat(path); err == nil {
+		startPos = fi.Size()
+	}
+
+	r := newFollowReader(ctx, path, startPos)
+	defer r.Close()
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return
+			}
+		}
+		if err != nil {
+			// followReader only ever returns an error once its ctx is
+			// done; there's no transient "no new data yet" case to retry
+			// since it blocks internally for that instead.
+			return
+		}
+	}
+}
@@ -2,9 +2,13 @@ package provider
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"os/exec"
+	"strconv"
 	"strings"
 
+	"github.com/virtual-kubelet/virtual-kubelet/log"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -117,6 +121,18 @@ func (p *ApptainerProvider) setupNodeCapacity(ctx context.Context) error {
 	p.memory = "512Gi"
 	p.pods = "640"
 
+	if p.defaultRuntime == RuntimeSlurm {
+		if cpu, memory, gpu, err := sinfoPartitionTotals(ctx, p.slurmPartition); err != nil {
+			log.G(ctx).WithError(err).Warn("failed to query sinfo for partition totals, falling back to defaults")
+		} else {
+			p.cpu = cpu
+			p.memory = memory
+			if gpu != "" {
+				p.gpu = gpu
+			}
+		}
+	}
+
 	if cpuQuota := os.Getenv("APPTAINER_QUOTA_CPU"); cpuQuota != "" {
 		p.cpu = cpuQuota
 	}
@@ -132,3 +148,67 @@ func (p *ApptainerProvider) setupNodeCapacity(ctx context.Context) error {
 
 	return nil
 }
+
+// sinfoPartitionTotals sums the CPU, memory, and GPU count of every node in
+// partition, so a SLURM-backed node advertises that partition's real
+// capacity instead of the hard-coded defaults above. partition is required:
+// without it sinfo reports every node in the cluster, including nodes that
+// belong to more than one partition, which both overstates capacity and can
+// double-count a shared node.
+func sinfoPartitionTotals(ctx context.Context, partition string) (cpu, memory, gpu string, err error) {
+	if partition == "" {
+		return "", "", "", fmt.Errorf("no slurm partition configured, refusing to guess cluster-wide totals")
+	}
+
+	out, err := exec.CommandContext(ctx, "sinfo", "-N", "-h", "-p", partition, "-o", "%C %m %G").Output()
+	if err != nil {
+		return "", "", "", err
+	}
+
+	var totalCPU, totalMemMB, totalGPU int64
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		// %C reports "allocated/idle/other/total"; we want the total.
+		cpuFields := strings.Split(fields[0], "/")
+		if n, err := strconv.ParseInt(cpuFields[len(cpuFields)-1], 10, 64); err == nil {
+			totalCPU += n
+		}
+		if n, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+			totalMemMB += n
+		}
+		if len(fields) >= 3 {
+			totalGPU += parseGresGPUCount(fields[2])
+		}
+	}
+
+	if totalCPU == 0 {
+		return "", "", "", fmt.Errorf("sinfo returned no parseable partition totals")
+	}
+
+	gpu = ""
+	if totalGPU > 0 {
+		gpu = strconv.FormatInt(totalGPU, 10)
+	}
+	return strconv.FormatInt(totalCPU, 10), strconv.FormatInt(totalMemMB, 10) + "Mi", gpu, nil
+}
+
+// parseGresGPUCount sums the counts of every "gpu[:type]:count" entry in a
+// %G gres field (e.g. "gpu:a100:4,gpu:v100:2" or "gpu:2"); a node with no
+// GPU gres reports "(null)", which has no such entry and contributes 0.
+func parseGresGPUCount(gres string) int64 {
+	var total int64
+	for _, entry := range strings.Split(gres, ",") {
+		parts := strings.Split(entry, ":")
+		if len(parts) < 2 || parts[0] != "gpu" {
+			continue
+		}
+		if n, err := strconv.ParseInt(parts[len(parts)-1], 10, 64); err == nil {
+			total += n
+		}
+	}
+	return total
+}
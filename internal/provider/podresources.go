@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"github.com/batx-dev/virtual-kubelet-apptainer/internal/podresources"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// visibleDevicesEnvVar is the environment variable Apptainer's --nv support
+// (and the Nvidia container stack generally) reads to restrict which GPUs a
+// container can see.
+const visibleDevicesEnvVar = "NVIDIA_VISIBLE_DEVICES"
+
+// PodResources implements podresources.PodSource, giving the PodResources
+// gRPC server the set of pods/containers this provider knows about and the
+// GPUs each one is allowed to see.
+func (p *ApptainerProvider) PodResources() []podresources.PodRef {
+	recs := p.registry.list()
+	refs := make([]podresources.PodRef, 0, len(recs))
+	for _, rec := range recs {
+		rec.mu.RLock()
+		ref := podresources.PodRef{
+			Namespace: rec.Pod.Namespace,
+			Name:      rec.Pod.Name,
+			UID:       string(rec.Pod.UID),
+		}
+		for _, c := range rec.Pod.Spec.Containers {
+			if _, ok := rec.Containers[c.Name]; !ok {
+				continue
+			}
+			_, gpu := c.Resources.Limits[gpuResourceName]
+			cref := podresources.ContainerRef{Name: c.Name, GPU: gpu}
+			if gpu {
+				cref.VisibleDevices = visibleDevicesFor(&c)
+			}
+			ref.Containers = append(ref.Containers, cref)
+		}
+		rec.mu.RUnlock()
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// visibleDevicesFor returns the NVIDIA_VISIBLE_DEVICES value a container
+// requested, defaulting to "all" the way Apptainer's --nv does when the
+// container doesn't set one.
+func visibleDevicesFor(c *corev1.Container) string {
+	for _, e := range c.Env {
+		if e.Name == visibleDevicesEnvVar {
+			return e.Value
+		}
+	}
+	return "all"
+}
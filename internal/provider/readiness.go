@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+)
+
+// Exec implements readiness.Executor, running a probe command inside a
+// container's Apptainer instance and reporting only whether it succeeded;
+// probes don't need the output, only the exit code.
+func (p *ApptainerProvider) Exec(ctx context.Context, namespace, podName, containerName string, cmd []string) error {
+	inst := instanceName(namespace, podName, containerName)
+	args := append([]string{"exec", fmt.Sprintf("instance://%s", inst)}, cmd...)
+	return exec.CommandContext(ctx, "apptainer", args...).Run()
+}
+
+// applyReadiness runs this poll's readiness probes for rec's pod and
+// overlays the result onto its container statuses, returning whether any
+// container's Ready flag changed so the caller knows whether to notify.
+//
+// Probing is only implemented for the local runtime: Exec (above) always
+// execs into a local Apptainer instance, which doesn't exist for a slurm
+// pod's containers, so running a readinessProbe there would just fail
+// forever. Until probes are routed through the owning runtime instead, a
+// slurm pod with a readinessProbe keeps whatever Ready value
+// refreshPodStatus last derived from the job's own state.
+//
+// Evaluate runs probe commands, which can take up to the configured probe
+// timeout; it deliberately runs with rec unlocked so it doesn't hold up
+// GetPod/GetPodStatus/GetPods/ListPods/PodResources for that long, and only
+// takes the write lock to apply the already-computed result.
+func (p *ApptainerProvider) applyReadiness(ctx context.Context, rec *podRecord) bool {
+	rec.mu.RLock()
+	runtimeKind := rec.RuntimeKind
+	pod := rec.Pod
+	rec.mu.RUnlock()
+
+	if runtimeKind != RuntimeLocal {
+		return false
+	}
+
+	running := make(map[string]bool, len(pod.Status.ContainerStatuses))
+	for _, cs := range pod.Status.ContainerStatuses {
+		running[cs.Name] = cs.State.Running != nil
+	}
+
+	ready := p.readiness.Evaluate(ctx, pod, running)
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	changed := false
+	for i, cs := range rec.Pod.Status.ContainerStatuses {
+		// A container missing from ready (not running, or blocked on its
+		// init containers or wait-for peer) is not Ready.
+		r := ready[cs.Name]
+		if r == cs.Ready {
+			continue
+		}
+		rec.Pod.Status.ContainerStatuses[i].Ready = r
+		changed = true
+	}
+
+	rec.Pod.Status.Conditions = podConditions(rec.Pod.Status.Conditions, rec.Pod.Status.ContainerStatuses)
+	return changed
+}
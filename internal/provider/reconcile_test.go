@@ -0,0 +1,91 @@
+package provider
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/batx-dev/virtual-kubelet-apptainer/internal/readiness"
+	"github.com/virtual-kubelet/virtual-kubelet/node/api"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeRuntime reports a fixed RuntimeStatus for every Status call, so tests
+// can drive reconcileOnce without shelling out to a real scheduler.
+type fakeRuntime struct {
+	status RuntimeStatus
+}
+
+func (f *fakeRuntime) Submit(ctx context.Context, pod *corev1.Pod) (string, error) { return "", nil }
+func (f *fakeRuntime) Status(ctx context.Context, jobID string) (RuntimeStatus, error) {
+	return f.status, nil
+}
+func (f *fakeRuntime) Cancel(ctx context.Context, jobID string) error { return nil }
+func (f *fakeRuntime) Logs(ctx context.Context, jobID, container string) (string, error) {
+	return "", nil
+}
+func (f *fakeRuntime) Exec(ctx context.Context, jobID, container string, cmd []string, attach api.AttachIO) error {
+	return nil
+}
+
+// noopExecutor satisfies readiness.Executor without ever being called: the
+// test pod has no readiness probes, so Evaluate never reaches Exec.
+type noopExecutor struct{}
+
+func (noopExecutor) Exec(ctx context.Context, namespace, podName, containerName string, cmd []string) error {
+	return nil
+}
+
+func TestReconcileOnceDeliversSchedulerBackendDisruption(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "batch-job"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "main"}},
+		},
+	}
+
+	rec := &podRecord{
+		Pod:         pod,
+		RuntimeKind: RuntimeSlurm,
+		JobID:       "123",
+		Containers:  map[string]*containerRecord{"main": {Name: "main"}},
+	}
+
+	reg := &registry{path: filepath.Join(t.TempDir(), "registry.json"), byKey: map[string]*podRecord{
+		podKey(pod.Namespace, pod.Name): rec,
+	}}
+
+	var notified *corev1.Pod
+	p := &ApptainerProvider{
+		registry: reg,
+		runtimes: map[string]Runtime{
+			RuntimeSlurm: &fakeRuntime{status: RuntimeStatus{
+				Phase: corev1.PodFailed,
+				Containers: []RuntimeContainerStatus{{
+					Name:  "main",
+					State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Reason: "OOMKilled"}},
+				}},
+			}},
+		},
+		defaultRuntime: RuntimeLocal,
+		readiness:      readiness.NewTracker(noopExecutor{}, readiness.Config{}),
+		notify:         func(p *corev1.Pod) { notified = p },
+	}
+
+	p.reconcileOnce(context.Background())
+
+	if notified == nil {
+		t.Fatal("expected reconcileOnce to notify on the failed job")
+	}
+
+	var reason string
+	for _, c := range notified.Status.Conditions {
+		if c.Type == corev1.DisruptionTarget {
+			reason = c.Reason
+		}
+	}
+	if reason != string(ReasonTerminationBySchedulerBackend) {
+		t.Fatalf("expected notified pod to carry reason %q, got %q", ReasonTerminationBySchedulerBackend, reason)
+	}
+}
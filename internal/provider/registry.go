@@ -0,0 +1,159 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// containerRecord tracks the state we've last observed for a single
+// container of a pod, so we can tell the difference between "never
+// started" and "ran, then exited" when a Runtime stops reporting it.
+type containerRecord struct {
+	Name         string    `json:"name"`
+	LogPath      string    `json:"logPath"`
+	RestartCount int32     `json:"restartCount"`
+	StartedAt    time.Time `json:"startedAt"`
+}
+
+// podRecord is the persisted view of a pod this provider is running. mu
+// guards Pod, RuntimeKind, JobID, and Containers against the concurrent
+// reads GetPod/GetPodStatus/GetPods/ListPods/PodResources do against the
+// same record reconcileOnce is mutating in place; the registry's own mutex
+// only protects the byKey map, not what its values point to. It isn't
+// serialized - a record loaded from disk starts unlocked, which is the only
+// valid state for one nothing yet holds a reference to.
+type podRecord struct {
+	Pod         *corev1.Pod                 `json:"pod"`
+	RuntimeKind string                      `json:"runtimeKind"`
+	JobID       string                      `json:"jobID"`
+	Containers  map[string]*containerRecord `json:"containers"`
+
+	mu sync.RWMutex
+}
+
+// registry is the in-memory (and disk-backed) mapping of pods this provider
+// knows about to the Apptainer instances it started for them. It is
+// persisted under $XDG_STATE_HOME/vk-apptainer so that a restart of the
+// virtual-kubelet process reconciles against the instances Apptainer still
+// has running rather than orphaning them.
+type registry struct {
+	mu    sync.RWMutex
+	path  string
+	byKey map[string]*podRecord
+}
+
+func podKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+// newRegistry loads the on-disk registry if one exists, or starts empty.
+func newRegistry() (*registry, error) {
+	path, err := registryStatePath()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &registry{
+		path:  path,
+		byKey: make(map[string]*podRecord),
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return r, nil
+		}
+		return nil, fmt.Errorf("reading pod registry %s: %w", path, err)
+	}
+	defer f.Close()
+
+	if err := json.NewDecoder(f).Decode(&r.byKey); err != nil {
+		return nil, fmt.Errorf("decoding pod registry %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// registryStatePath resolves $XDG_STATE_HOME/vk-apptainer/registry.json,
+// falling back to ~/.local/state/vk-apptainer when XDG_STATE_HOME is unset.
+func registryStatePath() (string, error) {
+	stateHome := os.Getenv("XDG_STATE_HOME")
+	if stateHome == "" {
+		home, err := homedir.Dir()
+		if err != nil {
+			return "", fmt.Errorf("resolving home directory: %w", err)
+		}
+		stateHome = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(stateHome, "vk-apptainer", "registry.json"), nil
+}
+
+func (r *registry) put(namespace, name string, rec *podRecord) error {
+	r.mu.Lock()
+	r.byKey[podKey(namespace, name)] = rec
+	r.mu.Unlock()
+	return r.save()
+}
+
+func (r *registry) delete(namespace, name string) error {
+	r.mu.Lock()
+	delete(r.byKey, podKey(namespace, name))
+	r.mu.Unlock()
+	return r.save()
+}
+
+func (r *registry) get(namespace, name string) (*podRecord, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rec, ok := r.byKey[podKey(namespace, name)]
+	return rec, ok
+}
+
+func (r *registry) list() []*podRecord {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]*podRecord, 0, len(r.byKey))
+	for _, rec := range r.byKey {
+		out = append(out, rec)
+	}
+	return out
+}
+
+// save persists the registry to disk. Callers already hold no lock; save
+// takes its own read lock so it can also be called from put/delete after
+// they release the write lock.
+func (r *registry) save() error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("creating registry state dir: %w", err)
+	}
+
+	tmp := r.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("creating registry temp file: %w", err)
+	}
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r.byKey); err != nil {
+		f.Close()
+		return fmt.Errorf("encoding pod registry: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("closing registry temp file: %w", err)
+	}
+
+	if err := os.Rename(tmp, r.path); err != nil {
+		return fmt.Errorf("replacing registry file: %w", err)
+	}
+	return nil
+}
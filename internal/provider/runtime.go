@@ -0,0 +1,106 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/virtual-kubelet/virtual-kubelet/node/api"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// RuntimeLocal runs pods directly on the current host via
+	// `apptainer instance`.
+	RuntimeLocal = "local"
+	// RuntimeSlurm submits pods as SLURM batch jobs wrapping `apptainer`.
+	RuntimeSlurm = "slurm"
+
+	// runtimeAnnotation overrides the provider's default --runtime for a
+	// single pod.
+	runtimeAnnotation = "apptainer.virtual-kubelet.io/runtime"
+
+	// SLURM job customization, read off the pod when RuntimeSlurm is used.
+	slurmPartitionAnnotation = "apptainer.virtual-kubelet.io/partition"
+	slurmQOSAnnotation       = "apptainer.virtual-kubelet.io/qos"
+	slurmAccountAnnotation   = "apptainer.virtual-kubelet.io/account"
+	slurmTimeLimitAnnotation = "apptainer.virtual-kubelet.io/time-limit"
+	slurmNodesAnnotation     = "apptainer.virtual-kubelet.io/nodes"
+)
+
+// RuntimeContainerStatus is a Runtime's view of a single container's state,
+// translated into the Kubernetes vocabulary so the provider can drop it
+// straight into a corev1.ContainerStatus.
+type RuntimeContainerStatus struct {
+	Name         string
+	State        corev1.ContainerState
+	RestartCount int32
+}
+
+// RuntimeStatus is a Runtime's view of an entire submitted pod.
+type RuntimeStatus struct {
+	Phase      corev1.PodPhase
+	Containers []RuntimeContainerStatus
+}
+
+// Runtime is the execution backend that actually runs a pod's containers.
+// ApptainerProvider talks to pods only through this interface, so that the
+// same CreatePod/DeletePod/GetPodStatus plumbing works whether a pod's
+// containers run as local `apptainer instance`s or as a SLURM batch job
+// wrapping `apptainer exec`.
+type Runtime interface {
+	// Submit starts the containers of pod and returns an opaque job ID the
+	// other methods use to refer back to this submission.
+	Submit(ctx context.Context, pod *corev1.Pod) (jobID string, err error)
+
+	// Status reports the current state of a previously submitted job.
+	Status(ctx context.Context, jobID string) (RuntimeStatus, error)
+
+	// Cancel tears down a previously submitted job.
+	Cancel(ctx context.Context, jobID string) error
+
+	// Logs returns the path to the raw log file of a single container of
+	// job. Callers are responsible for applying any api.ContainerLogOpts
+	// semantics (tailing, since-time, etc) when reading it.
+	Logs(ctx context.Context, jobID, container string) (path string, err error)
+
+	// Exec runs cmd inside container of job, wiring attach's streams to it.
+	Exec(ctx context.Context, jobID, container string, cmd []string, attach api.AttachIO) error
+}
+
+// RuntimeConfig carries the --runtime flag and its SLURM-specific defaults
+// in from cmd/virtual-kubelet.
+type RuntimeConfig struct {
+	Default string
+
+	SlurmPartition string
+	SlurmQOS       string
+	SlurmAccount   string
+	SlurmTimeLimit string
+	SlurmNodes     string
+	SlurmWorkDir   string
+}
+
+// newRuntimes builds the set of Runtimes this provider can dispatch pods to.
+// ctx is the provider's own lifetime context, used for background work like
+// local's log forwarders that must outlive any single CreatePod call.
+func newRuntimes(ctx context.Context, cfg RuntimeConfig) map[string]Runtime {
+	return map[string]Runtime{
+		RuntimeLocal: newLocalRuntime(ctx),
+		RuntimeSlurm: newSlurmRuntime(slurmDefaults{
+			Partition: cfg.SlurmPartition,
+			QOS:       cfg.SlurmQOS,
+			Account:   cfg.SlurmAccount,
+			TimeLimit: cfg.SlurmTimeLimit,
+			Nodes:     cfg.SlurmNodes,
+		}, cfg.SlurmWorkDir),
+	}
+}
+
+// runtimeNameFor returns the runtime a pod should use: its own
+// apptainer.virtual-kubelet.io/runtime annotation if set, otherwise the
+// provider's configured default.
+func runtimeNameFor(pod *corev1.Pod, def string) string {
+	if name, ok := pod.Annotations[runtimeAnnotation]; ok && name != "" {
+		return name
+	}
+	return def
+}
@@ -0,0 +1,135 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/virtual-kubelet/virtual-kubelet/node/api"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// localRuntime runs a pod's containers directly on the current host, one
+// `apptainer instance` per container. It is the runtime implied by the
+// original single-node provider.
+type localRuntime struct {
+	// ctx is the provider's own lifetime context, outliving any single
+	// Submit call, so log forwarders started here keep running until the
+	// provider itself shuts down.
+	ctx context.Context
+}
+
+func newLocalRuntime(ctx context.Context) *localRuntime {
+	return &localRuntime{ctx: ctx}
+}
+
+// Submit starts one `apptainer instance start` per container and returns
+// the pod's job ID, which for the local runtime is just its namespace/name
+// joined the same way instance names are, so Status can recover the
+// container list by prefix-matching `apptainer instance list`.
+func (r *localRuntime) Submit(ctx context.Context, pod *corev1.Pod) (string, error) {
+	jobID := localJobID(pod.Namespace, pod.Name)
+
+	for i := range pod.Spec.Containers {
+		c := &pod.Spec.Containers[i]
+		inst := instanceName(pod.Namespace, pod.Name, c.Name)
+
+		if err := prepareLogDir(pod.Namespace, pod.Name, c.Name); err != nil {
+			return "", fmt.Errorf("preparing log directory for container %s: %w", c.Name, err)
+		}
+
+		args := startInstanceArgs(pod, c, inst)
+		if _, err := runApptainer(c, args...); err != nil {
+			return "", fmt.Errorf("starting apptainer instance for container %s: %w", c.Name, err)
+		}
+
+		startLogForwarder(r.ctx, inst, containerLogFile(pod.Namespace, pod.Name, c.Name))
+	}
+
+	return jobID, nil
+}
+
+func localJobID(namespace, name string) string {
+	return fmt.Sprintf("%s_%s", namespace, name)
+}
+
+// Status reports a RuntimeContainerStatus for every instance currently
+// running under jobID's prefix. Containers that never show up here (not yet
+// started, or already exited) are left for the caller to reconcile against
+// the pod's previous status, since the local runtime has no memory of a
+// container once its instance is gone.
+func (r *localRuntime) Status(ctx context.Context, jobID string) (RuntimeStatus, error) {
+	running, err := listInstances()
+	if err != nil {
+		return RuntimeStatus{}, err
+	}
+
+	prefix := jobID + "_"
+	var statuses []RuntimeContainerStatus
+	for name := range running {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		containerName := strings.TrimPrefix(name, prefix)
+		statuses = append(statuses, RuntimeContainerStatus{
+			Name: containerName,
+			State: corev1.ContainerState{
+				Running: &corev1.ContainerStateRunning{
+					StartedAt: metav1.Now(),
+				},
+			},
+		})
+	}
+
+	return RuntimeStatus{Containers: statuses}, nil
+}
+
+// Cancel stops every instance belonging to jobID.
+func (r *localRuntime) Cancel(ctx context.Context, jobID string) error {
+	running, err := listInstances()
+	if err != nil {
+		return err
+	}
+
+	prefix := jobID + "_"
+	var firstErr error
+	for name := range running {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if _, err := runApptainer(nil, "instance", "stop", name); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Logs returns the path to the container's log file, kept up to date by the
+// log forwarder Submit started for it.
+func (r *localRuntime) Logs(ctx context.Context, jobID, container string) (string, error) {
+	namespace, podName, ok := splitLocalJobID(jobID)
+	if !ok {
+		return "", fmt.Errorf("malformed local job ID %q", jobID)
+	}
+	return containerLogFile(namespace, podName, container), nil
+}
+
+// Exec runs cmd inside the container's instance, using a pseudo-terminal
+// when attach asks for one.
+func (r *localRuntime) Exec(ctx context.Context, jobID, container string, cmd []string, attach api.AttachIO) error {
+	namespace, podName, ok := splitLocalJobID(jobID)
+	if !ok {
+		return fmt.Errorf("malformed local job ID %q", jobID)
+	}
+	inst := instanceName(namespace, podName, container)
+	return execInInstance(ctx, inst, cmd, attach)
+}
+
+func splitLocalJobID(jobID string) (namespace, podName string, ok bool) {
+	parts := strings.SplitN(jobID, "_", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
@@ -0,0 +1,329 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/virtual-kubelet/virtual-kubelet/node/api"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// slurmDefaults are the fallback SLURM job parameters used when a pod
+// doesn't override them via annotation.
+type slurmDefaults struct {
+	Partition string
+	QOS       string
+	Account   string
+	TimeLimit string
+	Nodes     string
+}
+
+// slurmJob is what we remember in-memory about a submission, since a SLURM
+// job ID carries no information about the pod it came from. This is lost on
+// a virtual-kubelet restart, same caveat as the local runtime's instance
+// naming convention.
+type slurmJob struct {
+	namespace  string
+	podName    string
+	containers []string
+	images     map[string]string
+}
+
+// slurmRuntime submits a pod as a single SLURM batch job wrapping
+// `apptainer exec` for each container, for HPC deployments
+// where workloads must go through a scheduler rather than running directly
+// on the login node.
+type slurmRuntime struct {
+	defaults slurmDefaults
+	workDir  string
+
+	mu   sync.RWMutex
+	jobs map[string]*slurmJob
+}
+
+func newSlurmRuntime(defaults slurmDefaults, workDir string) *slurmRuntime {
+	return &slurmRuntime{
+		defaults: defaults,
+		workDir:  workDir,
+		jobs:     make(map[string]*slurmJob),
+	}
+}
+
+var sbatchScriptTemplate = template.Must(template.New("sbatch").Parse(`#!/bin/bash
+#SBATCH --job-name={{.JobName}}
+#SBATCH --partition={{.Partition}}
+{{- if .QOS}}
+#SBATCH --qos={{.QOS}}
+{{- end}}
+{{- if .Account}}
+#SBATCH --account={{.Account}}
+{{- end}}
+#SBATCH --time={{.TimeLimit}}
+#SBATCH --nodes={{.Nodes}}
+#SBATCH --output={{.OutputPath}}
+
+set -e
+
+pids=""
+{{range .Containers}}
+{{.EnvExports}}
+apptainer exec {{.Flags}} {{.Image}} {{.Command}} &
+pids="$pids $!"
+{{end}}
+
+status=0
+for pid in $pids; do
+	# wait runs in the foreground, but guarding it with the if keeps set -e
+	# from bailing out on the first failed container instead of reaping
+	# (and reporting the exit status of) every one of them.
+	if ! wait "$pid"; then
+		status=1
+	fi
+done
+exit $status
+`))
+
+type sbatchContainer struct {
+	EnvExports string
+	Flags      string
+	Image      string
+	Command    string
+}
+
+type sbatchData struct {
+	JobName    string
+	Partition  string
+	QOS        string
+	Account    string
+	TimeLimit  string
+	Nodes      string
+	OutputPath string
+	Containers []sbatchContainer
+}
+
+// Submit renders an sbatch script for pod and submits it with
+// `sbatch --parsable`, returning the numeric SLURM job ID.
+func (r *slurmRuntime) Submit(ctx context.Context, pod *corev1.Pod) (string, error) {
+	if err := os.MkdirAll(r.workDir, 0o755); err != nil {
+		return "", fmt.Errorf("creating slurm work dir: %w", err)
+	}
+
+	jobName := fmt.Sprintf("%s_%s", pod.Namespace, pod.Name)
+	scriptPath := filepath.Join(r.workDir, jobName+".sbatch")
+	outputPath := filepath.Join(r.workDir, jobName+".out")
+
+	data := sbatchData{
+		JobName:    jobName,
+		Partition:  annotationOrDefault(pod, slurmPartitionAnnotation, r.defaults.Partition),
+		QOS:        annotationOrDefault(pod, slurmQOSAnnotation, r.defaults.QOS),
+		Account:    annotationOrDefault(pod, slurmAccountAnnotation, r.defaults.Account),
+		TimeLimit:  annotationOrDefault(pod, slurmTimeLimitAnnotation, r.defaults.TimeLimit),
+		Nodes:      annotationOrDefault(pod, slurmNodesAnnotation, r.defaults.Nodes),
+		OutputPath: outputPath,
+	}
+
+	containers := make([]string, 0, len(pod.Spec.Containers))
+	images := make(map[string]string, len(pod.Spec.Containers))
+	for i := range pod.Spec.Containers {
+		c := &pod.Spec.Containers[i]
+		containers = append(containers, c.Name)
+		images[c.Name] = imageURI(c.Image)
+		data.Containers = append(data.Containers, sbatchContainer{
+			EnvExports: strings.Join(envArgs(c), "\n"),
+			Flags:      strings.Join(append(append(gpuArgs(c), securityArgs(c)...), bindArgs(pod, c)...), " "),
+			Image:      imageURI(c.Image),
+			Command:    strings.Join(append(c.Command, c.Args...), " "),
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := sbatchScriptTemplate.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("rendering sbatch script: %w", err)
+	}
+	if err := os.WriteFile(scriptPath, buf.Bytes(), 0o755); err != nil {
+		return "", fmt.Errorf("writing sbatch script: %w", err)
+	}
+
+	out, err := exec.CommandContext(ctx, "sbatch", "--parsable", scriptPath).Output()
+	if err != nil {
+		return "", fmt.Errorf("sbatch submit: %w", err)
+	}
+	jobID := strings.TrimSpace(string(out))
+	// --parsable prints "jobID" or "jobID;cluster"; keep just the ID.
+	jobID = strings.SplitN(jobID, ";", 2)[0]
+
+	r.mu.Lock()
+	r.jobs[jobID] = &slurmJob{namespace: pod.Namespace, podName: pod.Name, containers: containers, images: images}
+	r.mu.Unlock()
+
+	return jobID, nil
+}
+
+func annotationOrDefault(pod *corev1.Pod, key, def string) string {
+	if v, ok := pod.Annotations[key]; ok && v != "" {
+		return v
+	}
+	return def
+}
+
+// slurmStateToContainerState maps the state codes squeue/sacct report
+// (PD, R, CG, CD, F, TO, CA, ...) onto a corev1.ContainerState.
+func slurmStateToContainerState(state string) corev1.ContainerState {
+	switch state {
+	case "PD":
+		return corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "SlurmPending"}}
+	case "R", "CG":
+		return corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+	case "CD":
+		return corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0, Reason: "SlurmCompleted"}}
+	case "F":
+		return corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Reason: "SlurmFailed"}}
+	case "TO":
+		return corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Reason: "SlurmTimeout"}}
+	case "CA":
+		return corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Reason: "SlurmCancelled"}}
+	default:
+		return corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "SlurmUnknown"}}
+	}
+}
+
+func slurmStateToPodPhase(state string) corev1.PodPhase {
+	switch state {
+	case "PD":
+		return corev1.PodPending
+	case "R", "CG":
+		return corev1.PodRunning
+	case "CD":
+		return corev1.PodSucceeded
+	case "F", "TO", "CA":
+		return corev1.PodFailed
+	default:
+		return corev1.PodPending
+	}
+}
+
+// Status queries squeue for jobID, falling back to sacct once the job has
+// left the queue (squeue only reports currently-queued/running jobs).
+func (r *slurmRuntime) Status(ctx context.Context, jobID string) (RuntimeStatus, error) {
+	state, err := r.queryState(ctx, jobID)
+	if err != nil {
+		return RuntimeStatus{}, err
+	}
+
+	r.mu.RLock()
+	job := r.jobs[jobID]
+	r.mu.RUnlock()
+
+	var names []string
+	if job != nil {
+		names = job.containers
+	}
+
+	containerState := slurmStateToContainerState(state)
+	statuses := make([]RuntimeContainerStatus, 0, len(names))
+	for _, name := range names {
+		statuses = append(statuses, RuntimeContainerStatus{Name: name, State: containerState})
+	}
+
+	return RuntimeStatus{Phase: slurmStateToPodPhase(state), Containers: statuses}, nil
+}
+
+func (r *slurmRuntime) queryState(ctx context.Context, jobID string) (string, error) {
+	out, err := exec.CommandContext(ctx, "squeue", "-j", jobID, "-h", "-o", "%T").Output()
+	if err == nil && strings.TrimSpace(string(out)) != "" {
+		return slurmShortState(strings.TrimSpace(string(out))), nil
+	}
+
+	out, err = exec.CommandContext(ctx, "sacct", "-j", jobID, "-n", "-X", "-o", "State").Output()
+	if err != nil {
+		return "", fmt.Errorf("querying slurm job %s: %w", jobID, err)
+	}
+	return slurmShortState(strings.TrimSpace(string(out))), nil
+}
+
+// slurmShortState normalizes the verbose state names squeue/sacct print
+// ("PENDING", "RUNNING", "COMPLETED", ...) to the short codes used above.
+// OUT_OF_MEMORY and NODE_FAIL are folded into "F".
+func slurmShortState(long string) string {
+	switch strings.ToUpper(strings.Fields(long)[0]) {
+	case "PD", "PENDING":
+		return "PD"
+	case "R", "RUNNING":
+		return "R"
+	case "CG", "COMPLETING":
+		return "CG"
+	case "CD", "COMPLETED":
+		return "CD"
+	case "F", "FAILED", "OOM", "OUT_OF_MEMORY", "NF", "NODE_FAIL":
+		return "F"
+	case "TO", "TIMEOUT":
+		return "TO"
+	case "CA", "CANCELLED":
+		return "CA"
+	default:
+		return long
+	}
+}
+
+// Cancel runs `scancel` on the job.
+func (r *slurmRuntime) Cancel(ctx context.Context, jobID string) error {
+	if err := exec.CommandContext(ctx, "scancel", jobID).Run(); err != nil {
+		return fmt.Errorf("scancel %s: %w", jobID, err)
+	}
+	r.mu.Lock()
+	delete(r.jobs, jobID)
+	r.mu.Unlock()
+	return nil
+}
+
+// Logs returns the path to the job's combined sbatch --output file. SLURM
+// doesn't separate stdout by container since they all run inside the same
+// allocation, so every container of a job shares this file.
+func (r *slurmRuntime) Logs(ctx context.Context, jobID, container string) (string, error) {
+	r.mu.RLock()
+	job := r.jobs[jobID]
+	r.mu.RUnlock()
+	if job == nil {
+		return "", fmt.Errorf("unknown slurm job %s", jobID)
+	}
+	return filepath.Join(r.workDir, fmt.Sprintf("%s_%s.out", job.namespace, job.podName)), nil
+}
+
+// Exec attaches to the job's allocation via `srun --jobid` and runs cmd
+// against the same image the container was started from.
+//
+// Unlike the local runtime, sbatchScriptTemplate never creates a named
+// Apptainer instance for a container - all of a pod's containers run as
+// plain `apptainer exec` processes sharing one batch allocation - so there's
+// no `instance://<name>` for this to attach to. Joining the allocation and
+// running a fresh `apptainer exec` against the same image is the closest
+// approximation available: it lands in the same allocation and environment
+// the container runs in, but it is a new process, not a handle onto the
+// container's own running one.
+func (r *slurmRuntime) Exec(ctx context.Context, jobID, container string, cmd []string, attach api.AttachIO) error {
+	r.mu.RLock()
+	job := r.jobs[jobID]
+	r.mu.RUnlock()
+	if job == nil {
+		return fmt.Errorf("unknown slurm job %s", jobID)
+	}
+
+	image, ok := job.images[container]
+	if !ok {
+		return fmt.Errorf("unknown container %s in slurm job %s", container, jobID)
+	}
+
+	args := append([]string{"--jobid=" + jobID, "apptainer", "exec", image}, cmd...)
+	c := exec.CommandContext(ctx, "srun", args...)
+	c.Stdin = attach.Stdin()
+	c.Stdout = attach.Stdout()
+	c.Stderr = attach.Stderr()
+	return c.Run()
+}
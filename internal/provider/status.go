@@ -0,0 +1,224 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// apptainerInstance mirrors the subset of `apptainer instance list --json`
+// we care about.
+type apptainerInstance struct {
+	Instance string `json:"instance"`
+	Pid      int    `json:"pid"`
+	Image    string `json:"image"`
+}
+
+type apptainerInstanceList struct {
+	Instances []apptainerInstance `json:"instances"`
+}
+
+// listInstances shells out to `apptainer instance list --json` and returns
+// the running instances keyed by instance name.
+func listInstances() (map[string]apptainerInstance, error) {
+	out, err := exec.Command("apptainer", "instance", "list", "--json").Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing apptainer instances: %w", err)
+	}
+
+	var list apptainerInstanceList
+	if err := json.Unmarshal(out, &list); err != nil {
+		return nil, fmt.Errorf("decoding apptainer instance list: %w", err)
+	}
+
+	byName := make(map[string]apptainerInstance, len(list.Instances))
+	for _, inst := range list.Instances {
+		byName[inst.Instance] = inst
+	}
+	return byName, nil
+}
+
+// refreshPodStatus merges a Runtime's view of rec's job into rec.Pod.Status,
+// returning true if anything changed so the caller can decide whether to
+// notify. Containers the runtime doesn't mention are reconciled against
+// their previous status: a container that was Running and has disappeared
+// is assumed to have exited; one already recorded as Terminated stays so;
+// one that was never seen is still Waiting.
+func refreshPodStatus(rec *podRecord, rs RuntimeStatus) bool {
+	changed := false
+	byName := make(map[string]RuntimeContainerStatus, len(rs.Containers))
+	for _, cs := range rs.Containers {
+		byName[cs.Name] = cs
+	}
+
+	statuses := make([]corev1.ContainerStatus, 0, len(rec.Containers))
+	for _, c := range rec.Pod.Spec.Containers {
+		cr, ok := rec.Containers[c.Name]
+		if !ok {
+			continue
+		}
+
+		rcs, seen := byName[c.Name]
+		prev := findContainerStatus(rec.Pod.Status.ContainerStatuses, c.Name)
+
+		var cs corev1.ContainerStatus
+		cs.Name = c.Name
+		cs.Image = c.Image
+		cs.RestartCount = cr.RestartCount
+
+		switch {
+		case seen:
+			cs.State = rcs.State
+			cs.Ready = cs.State.Running != nil
+		case prev != nil && prev.State.Running != nil:
+			// Was running last time we checked, isn't anymore: it exited.
+			exitCode, reason := containerExitStatus(rec.Pod.Namespace, rec.Pod.Name, c.Name)
+			cs.State = corev1.ContainerState{
+				Terminated: &corev1.ContainerStateTerminated{
+					ExitCode:   exitCode,
+					Reason:     reason,
+					StartedAt:  metav1.NewTime(cr.StartedAt),
+					FinishedAt: metav1.Now(),
+				},
+			}
+		case prev != nil && prev.State.Terminated != nil:
+			cs.State = prev.State
+		default:
+			cs.State = corev1.ContainerState{
+				Waiting: &corev1.ContainerStateWaiting{
+					Reason: "ContainerCreating",
+				},
+			}
+		}
+
+		if prev == nil || !containerStateEqual(prev.State, cs.State) {
+			changed = true
+		}
+
+		statuses = append(statuses, cs)
+	}
+
+	rec.Pod.Status.ContainerStatuses = statuses
+	if rs.Phase != "" {
+		rec.Pod.Status.Phase = rs.Phase
+	} else {
+		rec.Pod.Status.Phase = podPhase(statuses)
+	}
+	rec.Pod.Status.Conditions = podConditions(rec.Pod.Status.Conditions, statuses)
+	return changed
+}
+
+// containerExitStatus reads back the exit code wrapCommandForExitCode wrote
+// for a container whose instance has disappeared from `instance list`. If
+// the file is missing or unreadable the instance was killed before its
+// wrapper shell could write it (e.g. OOM, SIGKILL), which is itself a
+// failure rather than the "exited cleanly" default it would otherwise be
+// mistaken for.
+func containerExitStatus(namespace, podName, containerName string) (exitCode int32, reason string) {
+	raw, err := os.ReadFile(exitCodePath(namespace, podName, containerName))
+	if err != nil {
+		return 1, "Unknown"
+	}
+
+	n, err := strconv.ParseInt(strings.TrimSpace(string(raw)), 10, 32)
+	if err != nil {
+		return 1, "Unknown"
+	}
+	if n != 0 {
+		return int32(n), "Error"
+	}
+	return 0, "Completed"
+}
+
+func findContainerStatus(statuses []corev1.ContainerStatus, name string) *corev1.ContainerStatus {
+	for i := range statuses {
+		if statuses[i].Name == name {
+			return &statuses[i]
+		}
+	}
+	return nil
+}
+
+func containerStateEqual(a, b corev1.ContainerState) bool {
+	return (a.Running != nil) == (b.Running != nil) &&
+		(a.Waiting != nil) == (b.Waiting != nil) &&
+		(a.Terminated != nil) == (b.Terminated != nil)
+}
+
+// podPhase derives the pod-level phase from its container statuses:
+// Succeeded/Failed once every container has terminated (Failed if any exited
+// non-zero), Running while at least one container is running, Pending
+// otherwise.
+func podPhase(statuses []corev1.ContainerStatus) corev1.PodPhase {
+	if len(statuses) == 0 {
+		return corev1.PodPending
+	}
+
+	allTerminated := true
+	anyFailed := false
+	anyRunning := false
+	for _, cs := range statuses {
+		switch {
+		case cs.State.Running != nil:
+			anyRunning = true
+			allTerminated = false
+		case cs.State.Waiting != nil:
+			allTerminated = false
+		case cs.State.Terminated != nil:
+			if cs.State.Terminated.ExitCode != 0 {
+				anyFailed = true
+			}
+		}
+	}
+
+	switch {
+	case allTerminated && anyFailed:
+		return corev1.PodFailed
+	case allTerminated:
+		return corev1.PodSucceeded
+	case anyRunning:
+		return corev1.PodRunning
+	default:
+		return corev1.PodPending
+	}
+}
+
+// podConditions recomputes the PodReady/ContainersReady conditions from
+// statuses and merges them into existing, leaving every other condition
+// (notably DisruptionTarget, set by setDisruptionCondition) untouched rather
+// than clobbering it.
+func podConditions(existing []corev1.PodCondition, statuses []corev1.ContainerStatus) []corev1.PodCondition {
+	ready := corev1.ConditionTrue
+	for _, cs := range statuses {
+		if !cs.Ready {
+			ready = corev1.ConditionFalse
+			break
+		}
+	}
+
+	now := metav1.Now()
+	merged := make([]corev1.PodCondition, 0, len(existing)+2)
+	for _, c := range existing {
+		if c.Type != corev1.PodReady && c.Type != corev1.ContainersReady {
+			merged = append(merged, c)
+		}
+	}
+	return append(merged,
+		corev1.PodCondition{
+			Type:               corev1.PodReady,
+			Status:             ready,
+			LastTransitionTime: now,
+		},
+		corev1.PodCondition{
+			Type:               corev1.ContainersReady,
+			Status:             ready,
+			LastTransitionTime: now,
+		},
+	)
+}
@@ -0,0 +1,97 @@
+package readiness
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// runProbe executes a single readiness or liveness probe for container
+// inside its Apptainer instance.
+func runProbe(ctx context.Context, exec Executor, namespace, podName string, c *corev1.Container, probe *corev1.Probe) bool {
+	cmd, err := probeCommand(c, probe)
+	if err != nil {
+		return false
+	}
+	return exec.Exec(ctx, namespace, podName, c.Name, cmd) == nil
+}
+
+// probeCommand translates a corev1.Probe into the command to run inside the
+// container's Apptainer instance: an exec probe's command directly, and
+// httpGet/tcpSocket as a shell one-liner (curl, and a /dev/tcp redirect,
+// respectively) since probing from outside the instance's network
+// namespace isn't possible without a host-level network stack to probe
+// from.
+func probeCommand(c *corev1.Container, probe *corev1.Probe) ([]string, error) {
+	switch {
+	case probe.Exec != nil:
+		return probe.Exec.Command, nil
+
+	case probe.HTTPGet != nil:
+		port, err := resolvePort(c, probe.HTTPGet.Port)
+		if err != nil {
+			return nil, err
+		}
+		host := probe.HTTPGet.Host
+		if host == "" {
+			host = "127.0.0.1"
+		}
+		scheme := strings.ToLower(string(probe.HTTPGet.Scheme))
+		if scheme == "" {
+			scheme = "http"
+		}
+		path := probe.HTTPGet.Path
+		if path == "" {
+			path = "/"
+		}
+
+		args := []string{"curl", "-sf", "-o", "/dev/null"}
+		for _, h := range probe.HTTPGet.HTTPHeaders {
+			args = append(args, "-H", fmt.Sprintf("%s: %s", h.Name, h.Value))
+		}
+		args = append(args, fmt.Sprintf("%s://%s:%d%s", scheme, host, port, path))
+		return []string{"sh", "-c", shellJoin(args)}, nil
+
+	case probe.TCPSocket != nil:
+		port, err := resolvePort(c, probe.TCPSocket.Port)
+		if err != nil {
+			return nil, err
+		}
+		host := probe.TCPSocket.Host
+		if host == "" {
+			host = "127.0.0.1"
+		}
+		// /dev/tcp redirection is a bash extension, not a POSIX sh feature,
+		// so this must run under bash even though the httpGet case above
+		// is plain sh.
+		return []string{"bash", "-c", fmt.Sprintf("cat < /dev/null > /dev/tcp/%s/%d", host, port)}, nil
+
+	default:
+		return nil, fmt.Errorf("probe has no exec, httpGet, or tcpSocket handler set")
+	}
+}
+
+// resolvePort resolves a probe's port, which may name one of the
+// container's declared ports instead of giving a number directly.
+func resolvePort(c *corev1.Container, port intstr.IntOrString) (int32, error) {
+	if port.Type == intstr.Int {
+		return port.IntVal, nil
+	}
+	for _, p := range c.Ports {
+		if p.Name == port.StrVal {
+			return p.ContainerPort, nil
+		}
+	}
+	return 0, fmt.Errorf("container %s has no port named %q", c.Name, port.StrVal)
+}
+
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = "'" + strings.ReplaceAll(a, "'", `'\''`) + "'"
+	}
+	return strings.Join(quoted, " ")
+}
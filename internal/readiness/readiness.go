@@ -0,0 +1,68 @@
+// Package readiness computes when a pod should be reported Ready, borrowing
+// the resource-readiness model Helm's kube client uses for its own
+// ready.go/wait.go: init containers must finish first, then every regular
+// container must pass its own readinessProbe and any peer it's been told to
+// wait for. Tracker also runs livenessProbes; what happens to a container
+// that fails one is left to the caller (see ApptainerProvider.applyLiveness),
+// since only the caller knows how to restart it.
+package readiness
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// waitForAnnotationPrefix keys a per-container dependency. corev1.Container
+// has no Annotations field of its own, so - the same way Kubernetes keys
+// other per-container settings it bolted on later, e.g.
+// container.seccomp.security.alpha.kubernetes.io/<container> - the
+// dependency is a pod annotation named after the container it applies to:
+// "apptainer.virtual-kubelet.io/wait-for.sidecar": "main" blocks sidecar on
+// main being Ready first.
+const waitForAnnotationPrefix = "apptainer.virtual-kubelet.io/wait-for."
+
+// Executor runs a single probe command inside a container's Apptainer
+// instance and reports whether it exited zero. It's the primitive every
+// probe type is built on: an exec probe runs its command directly, while
+// httpGet/tcpSocket are translated into a shell one-liner, since there's no
+// host network stack to probe against, only the instance's own namespace.
+type Executor interface {
+	Exec(ctx context.Context, namespace, podName, containerName string, cmd []string) error
+}
+
+// Config controls the probe worker pool a Tracker hands work to.
+type Config struct {
+	// Workers bounds how many probes can run concurrently across all pods.
+	Workers int
+	// Timeout bounds a single probe invocation.
+	Timeout time.Duration
+}
+
+func waitForPeer(pod *corev1.Pod, containerName string) (string, bool) {
+	v, ok := pod.Annotations[waitForAnnotationPrefix+containerName]
+	return v, ok && v != ""
+}
+
+// initContainersSucceeded reports whether every init container of pod has
+// already completed, the same gate the kubelet applies before starting (and
+// therefore ever reporting Ready for) a pod's regular containers.
+func initContainersSucceeded(pod *corev1.Pod) bool {
+	if len(pod.Spec.InitContainers) == 0 {
+		return true
+	}
+
+	statuses := make(map[string]corev1.ContainerStatus, len(pod.Status.InitContainerStatuses))
+	for _, s := range pod.Status.InitContainerStatuses {
+		statuses[s.Name] = s
+	}
+
+	for _, c := range pod.Spec.InitContainers {
+		s, ok := statuses[c.Name]
+		if !ok || s.State.Terminated == nil || s.State.Terminated.ExitCode != 0 {
+			return false
+		}
+	}
+	return true
+}
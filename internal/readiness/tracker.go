@@ -0,0 +1,112 @@
+package readiness
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const defaultProbeTimeout = 5 * time.Second
+
+// Tracker evaluates readiness for pods on demand, bounding how many probes
+// can run concurrently across the whole node via its worker pool.
+type Tracker struct {
+	exec Executor
+	cfg  Config
+	sem  chan struct{}
+}
+
+// NewTracker returns a Tracker that runs probes through exec, at most
+// cfg.Workers at a time, each bounded by cfg.Timeout.
+func NewTracker(exec Executor, cfg Config) *Tracker {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 4
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultProbeTimeout
+	}
+	return &Tracker{exec: exec, cfg: cfg, sem: make(chan struct{}, cfg.Workers)}
+}
+
+// Evaluate runs readiness probes for every container of pod whose
+// dependencies are currently satisfied - its init containers have
+// succeeded, it's actually running, and any apptainer.virtual-kubelet.io/
+// wait-for peer is already Ready - and returns a Ready verdict per
+// container. A container missing from the result (not running yet, or
+// blocked on a peer/init container) should be treated as not Ready by the
+// caller.
+func (t *Tracker) Evaluate(ctx context.Context, pod *corev1.Pod, containerRunning map[string]bool) map[string]bool {
+	ready := make(map[string]bool, len(pod.Spec.Containers))
+	if !initContainersSucceeded(pod) {
+		return ready
+	}
+
+	pending := make(map[string]*corev1.Container, len(pod.Spec.Containers))
+	for i := range pod.Spec.Containers {
+		c := &pod.Spec.Containers[i]
+		pending[c.Name] = c
+	}
+
+	// Resolve in dependency order: a container whose wait-for peer isn't
+	// Ready yet is left pending and retried next pass, so a chain of any
+	// length settles within len(containers) passes. An unsatisfiable or
+	// cyclic dependency just leaves those containers never-Ready, which is
+	// a conservative failure mode that's visible in kubectl get pods.
+	for pass := 0; pass < len(pod.Spec.Containers) && len(pending) > 0; pass++ {
+		var resolved []string
+		for name, c := range pending {
+			if !containerRunning[name] {
+				continue
+			}
+			if peer, ok := waitForPeer(pod, name); ok && !ready[peer] {
+				continue
+			}
+			ready[name] = t.probeOne(ctx, pod, c)
+			resolved = append(resolved, name)
+		}
+		if len(resolved) == 0 {
+			break
+		}
+		for _, name := range resolved {
+			delete(pending, name)
+		}
+	}
+
+	return ready
+}
+
+func (t *Tracker) probeOne(ctx context.Context, pod *corev1.Pod, c *corev1.Container) bool {
+	if c.ReadinessProbe == nil {
+		return true
+	}
+	return t.probe(ctx, pod, c, c.ReadinessProbe)
+}
+
+// EvaluateLiveness runs the livenessProbe of every currently-running
+// container that has one and returns the names of the ones that failed.
+// Unlike Evaluate, there's no init-container or wait-for gating here:
+// liveness only means anything once a container is already up, and a
+// container with no livenessProbe is never considered to have failed one.
+func (t *Tracker) EvaluateLiveness(ctx context.Context, pod *corev1.Pod, containerRunning map[string]bool) []string {
+	var failed []string
+	for i := range pod.Spec.Containers {
+		c := &pod.Spec.Containers[i]
+		if c.LivenessProbe == nil || !containerRunning[c.Name] {
+			continue
+		}
+		if !t.probe(ctx, pod, c, c.LivenessProbe) {
+			failed = append(failed, c.Name)
+		}
+	}
+	return failed
+}
+
+func (t *Tracker) probe(ctx context.Context, pod *corev1.Pod, c *corev1.Container, probe *corev1.Probe) bool {
+	t.sem <- struct{}{}
+	defer func() { <-t.sem }()
+
+	pctx, cancel := context.WithTimeout(ctx, t.cfg.Timeout)
+	defer cancel()
+	return runProbe(pctx, t.exec, pod.Namespace, pod.Name, c, probe)
+}